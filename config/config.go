@@ -0,0 +1,280 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Config holds user preferences that survive restarts
+type Config struct {
+	Format    string `json:"format"`     // output format new cases start with: "mp4", "mkv" (remux only) or "mp3"
+	OutputDir string `json:"output_dir"` // where archives are written, empty means the working directory
+
+	MaxHeight     int `json:"max_height"`     // video resolution cap in pixels
+	MaxConcurrent int `json:"max_concurrent"` // simultaneous yt-dlp processes
+
+	AudioFormat  string `json:"audio_format"`  // audio codec: "mp3", "m4a", "opus", "flac" or "wav"
+	AudioQuality string `json:"audio_quality"` // lossy audio bitrate, e.g. "192K"
+	EmbedArt     bool   `json:"embed_art"`     // embed album art and tags in audio files
+
+	DownloadSubs bool     `json:"download_subs"`
+	SubLangs     []string `json:"sub_langs"`
+	Thumbnails   bool     `json:"thumbnails"` // save thumbnails for the preview pane
+
+	WriteMetadataFiles bool `json:"write_metadata_files"` // keep yt-dlp's .info.json and .description next to each archive
+
+	HistoryPath string `json:"history_path"` // archive metadata file
+	MaxHistory  int    `json:"max_history"`  // newest records to keep, 0 means unlimited
+
+	CookiesFromBrowser string `json:"cookies_from_browser"` // browser to borrow cookies from, e.g. "firefox"
+	Proxy              string `json:"proxy"`                // e.g. "http://proxy.corp:3128", falls back to HTTPS_PROXY
+
+	YtDlpPath string `json:"yt_dlp_path"` // yt-dlp binary to run, a name on PATH or a path
+
+	OutputTemplate string `json:"output_template"` // yt-dlp -o template, must contain a %(...)s field
+
+	ExtraArgs []string `json:"extra_args"` // passed to every download after the built-in flags, so they win
+
+	PlaylistStart int    `json:"playlist_start"` // first playlist video to queue, 1-based; 0 asks when a playlist is pasted
+	PlaylistEnd   int    `json:"playlist_end"`   // last playlist video to queue, 0 means through the end
+	PlaylistItems string `json:"playlist_items"` // explicit selection such as "1-10,15", overrides start and end
+
+	MaxPlaylistEntries int `json:"max_playlist_entries"` // most videos one playlist expands to
+
+	TitleTimeoutSeconds int `json:"title_timeout_seconds"` // how long to wait for a case title
+
+	Notify         bool   `json:"notify"`           // desktop notification when a case finishes
+	BellOnComplete bool   `json:"bell_on_complete"` // ring the terminal bell when a case finishes
+	QuietHours     string `json:"quiet_hours"`      // "HH:MM-HH:MM" range with no bell or notifications, e.g. "22:00-07:00"
+
+	AutoFillFromClipboard bool `json:"auto_fill_from_clipboard"` // pre-fill the input with a URL found on the clipboard at startup
+
+	RateLimit string `json:"rate_limit"` // bandwidth cap per download, e.g. "500K" or "2M"; empty means unlimited
+
+	ConcurrentFragments int `json:"concurrent_fragments"` // yt-dlp -N, 1 to 16
+
+	DryRun bool `json:"dry_run"` // new cases only collect metadata until started with G
+
+	LiveFromStart bool `json:"live_from_start"` // record live streams from their beginning instead of rejecting them
+
+	SplitChapters bool `json:"split_chapters"` // also save every chapter as its own file, e.g. for podcasts
+
+	Theme string `json:"theme"` // color scheme: "tva" or "mono"
+
+	LeftPaneRatio float64 `json:"left_pane_ratio"` // share of the window width taken by the queue and history pane
+	PreviewRatio  float64 `json:"preview_ratio"`   // share of the right column height taken by the preview, the timeline gets the rest
+
+	LogLines int `json:"log_lines"` // recent yt-dlp lines shown under the selected running case, 0 hides them
+
+	TickRateMS int `json:"tick_rate_ms"` // screen refresh interval while cases are in flight; idle screens refresh once a second
+
+	StallTimeoutSeconds int `json:"stall_timeout_seconds"` // flag downloads whose progress hasn't moved for this long, 0 disables
+
+	LargeDownloadMB int `json:"large_download_mb"` // confirm starting dry-run cases estimated above this size, 0 never asks
+
+	env         *envOverrides // set by ApplyEnv
+	saveBlocked error         // set by Load when a corrupted file couldn't be backed up
+}
+
+// envOverrides remembers the settings on either side of ApplyEnv, so Save
+// can keep environment values out of the file
+type envOverrides struct {
+	file    Config // before ApplyEnv
+	applied Config // right after it
+}
+
+// Default returns the configuration used when no file exists
+func Default() *Config {
+	return &Config{
+		Format:              "mp4",
+		YtDlpPath:           "yt-dlp",
+		MaxHeight:           2160,
+		MaxConcurrent:       3,
+		AudioFormat:         "mp3",
+		AudioQuality:        "192K",
+		SubLangs:            []string{"en"},
+		Thumbnails:          true,
+		HistoryPath:         "downloads.json",
+		OutputTemplate:      "%(title)s.%(ext)s",
+		MaxPlaylistEntries:  50,
+		TitleTimeoutSeconds: 10,
+		ConcurrentFragments: 1,
+		Theme:               "tva",
+		LeftPaneRatio:       0.35,
+		PreviewRatio:        0.5,
+		LogLines:            5,
+		TickRateMS:          100,
+		StallTimeoutSeconds: 60,
+		LargeDownloadMB:     2048,
+	}
+}
+
+// DefaultPath returns ~/.config/yeet-tube/config.json (or the OS equivalent)
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "config.json"
+	}
+	return filepath.Join(dir, "yeet-tube", "config.json")
+}
+
+// Load reads the config at path, falling back to defaults when it is missing
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		// the defaults used instead would replace the file on the first save
+		cfg, corrupt := Default(), &CorruptConfigError{Err: err}
+		if os.WriteFile(path+".bak", data, 0644) == nil {
+			corrupt.Backup = path + ".bak"
+		} else {
+			cfg.saveBlocked = corrupt
+		}
+		return cfg, corrupt
+	}
+	return cfg, nil
+}
+
+// CorruptConfigError is returned by Load when the config file isn't valid
+// JSON. The original was copied to Backup before the defaults took over;
+// when that failed, Save refuses to overwrite it.
+type CorruptConfigError struct {
+	Backup string // "" if the backup couldn't be written
+	Err    error
+}
+
+func (e *CorruptConfigError) Error() string {
+	if e.Backup == "" {
+		return fmt.Sprintf("config file is corrupted and won't be overwritten: %v", e.Err)
+	}
+	return fmt.Sprintf("config file is corrupted (backed up to %s): %v", e.Backup, e.Err)
+}
+
+func (e *CorruptConfigError) Unwrap() error {
+	return e.Err
+}
+
+// TitleTimeout returns the title fetch limit as a duration
+func (c *Config) TitleTimeout() time.Duration {
+	return time.Duration(c.TitleTimeoutSeconds) * time.Second
+}
+
+// TickRate returns the active refresh interval as a duration
+func (c *Config) TickRate() time.Duration {
+	return time.Duration(c.TickRateMS) * time.Millisecond
+}
+
+// PlaylistRange returns the configured playlist selection in
+// --playlist-items form, or "" when none is set
+func (c *Config) PlaylistRange() string {
+	switch {
+	case c.PlaylistItems != "":
+		return c.PlaylistItems
+	case c.PlaylistStart == 0 && c.PlaylistEnd == 0:
+		return ""
+	case c.PlaylistEnd == 0:
+		return strconv.Itoa(c.PlaylistStart) + "-"
+	default:
+		return strconv.Itoa(max(c.PlaylistStart, 1)) + "-" + strconv.Itoa(c.PlaylistEnd)
+	}
+}
+
+// StallTimeout returns the stall detection limit as a duration
+func (c *Config) StallTimeout() time.Duration {
+	return time.Duration(c.StallTimeoutSeconds) * time.Second
+}
+
+// ApplyEnv overrides settings from environment variables. The overrides
+// only last for this run: Save writes the values they replaced.
+func (c *Config) ApplyEnv() {
+	env := &envOverrides{file: *c}
+	defer func() {
+		env.applied = *c
+		c.env = env
+	}()
+
+	if bin := os.Getenv("YEET_YTDLP_PATH"); bin != "" {
+		c.YtDlpPath = bin
+	}
+	if dir := os.Getenv("YEET_OUTPUT_DIR"); dir != "" {
+		c.OutputDir = dir
+	}
+	if browser := os.Getenv("YEET_COOKIES_BROWSER"); browser != "" {
+		c.CookiesFromBrowser = browser
+	}
+	if c.Proxy == "" {
+		c.Proxy = os.Getenv("HTTPS_PROXY")
+	}
+	if rate := os.Getenv("YEET_RATE_LIMIT"); rate != "" {
+		c.RateLimit = rate
+	}
+	if n, err := strconv.Atoi(os.Getenv("YEET_CONCURRENT_FRAGMENTS")); err == nil {
+		c.ConcurrentFragments = n
+	}
+	if args, err := splitArgs(os.Getenv("YEET_YTDLP_ARGS")); err == nil && len(args) > 0 {
+		c.ExtraArgs = args
+	}
+}
+
+// Save writes the config to path, creating its directory if needed. It
+// refuses to when Load found the file corrupted and couldn't back it up.
+func (c *Config) Save(path string) error {
+	if c.saveBlocked != nil {
+		return c.saveBlocked
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(c.withoutEnv(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// withoutEnv returns c with every setting that still holds its ApplyEnv
+// value put back to what it was before; settings changed since are kept
+func (c *Config) withoutEnv() *Config {
+	saved := *c
+	if c.env == nil {
+		return &saved
+	}
+	file, applied := c.env.file, c.env.applied
+
+	if saved.YtDlpPath == applied.YtDlpPath {
+		saved.YtDlpPath = file.YtDlpPath
+	}
+	if saved.OutputDir == applied.OutputDir {
+		saved.OutputDir = file.OutputDir
+	}
+	if saved.CookiesFromBrowser == applied.CookiesFromBrowser {
+		saved.CookiesFromBrowser = file.CookiesFromBrowser
+	}
+	if saved.Proxy == applied.Proxy {
+		saved.Proxy = file.Proxy
+	}
+	if saved.RateLimit == applied.RateLimit {
+		saved.RateLimit = file.RateLimit
+	}
+	if saved.ConcurrentFragments == applied.ConcurrentFragments {
+		saved.ConcurrentFragments = file.ConcurrentFragments
+	}
+	if slices.Equal(saved.ExtraArgs, applied.ExtraArgs) {
+		saved.ExtraArgs = file.ExtraArgs
+	}
+	return &saved
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// clearEnv unsets every variable ApplyEnv reads for the rest of the test
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"YEET_YTDLP_PATH", "YEET_OUTPUT_DIR", "YEET_COOKIES_BROWSER", "HTTPS_PROXY", "YEET_RATE_LIMIT", "YEET_CONCURRENT_FRAGMENTS", "YEET_YTDLP_ARGS"} {
+		t.Setenv(name, "")
+	}
+}
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadMissingFileUsesDefaults(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("Load = %+v, want the defaults", cfg)
+	}
+}
+
+func TestLoadKeepsDefaultsForUnsetFields(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `{"format":"mkv"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Format != "mkv" {
+		t.Errorf("Format = %q, want the file's mkv", cfg.Format)
+	}
+	if cfg.MaxHeight != Default().MaxHeight || cfg.HistoryPath != Default().HistoryPath {
+		t.Errorf("unset fields lost their defaults: %+v", cfg)
+	}
+}
+
+func TestEnvOverridesFile(t *testing.T) {
+	clearEnv(t)
+	cfg, err := Load(writeConfig(t, `{"rate_limit":"1M","cookies_from_browser":"chrome","output_dir":"/file","extra_args":["--embed-chapters"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("YEET_RATE_LIMIT", "2M")
+	t.Setenv("YEET_COOKIES_BROWSER", "firefox")
+	t.Setenv("YEET_YTDLP_ARGS", "--sleep-interval 5")
+	t.Setenv("YEET_CONCURRENT_FRAGMENTS", "4")
+	cfg.ApplyEnv()
+
+	if cfg.RateLimit != "2M" || cfg.CookiesFromBrowser != "firefox" || cfg.ConcurrentFragments != 4 {
+		t.Errorf("env didn't win: rate %q, cookies %q, fragments %d", cfg.RateLimit, cfg.CookiesFromBrowser, cfg.ConcurrentFragments)
+	}
+	if !slices.Equal(cfg.ExtraArgs, []string{"--sleep-interval", "5"}) {
+		t.Errorf("ExtraArgs = %q, want the env's", cfg.ExtraArgs)
+	}
+	if cfg.OutputDir != "/file" {
+		t.Errorf("OutputDir = %q, want the file's value when the env is unset", cfg.OutputDir)
+	}
+}
+
+func TestProxyEnvOnlyFillsAnEmptyProxy(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("HTTPS_PROXY", "http://env.proxy:3128")
+
+	cfg := Default()
+	cfg.ApplyEnv()
+	if cfg.Proxy != "http://env.proxy:3128" {
+		t.Errorf("Proxy = %q, want HTTPS_PROXY", cfg.Proxy)
+	}
+
+	cfg = Default()
+	cfg.Proxy = "socks5://file.proxy:1080"
+	cfg.ApplyEnv()
+	if cfg.Proxy != "socks5://file.proxy:1080" {
+		t.Errorf("Proxy = %q, want the configured proxy", cfg.Proxy)
+	}
+}
+
+func TestSaveLeavesEnvOverridesOut(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, `{"rate_limit":"1M","cookies_from_browser":"chrome"}`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("YEET_RATE_LIMIT", "2M")
+	t.Setenv("YEET_COOKIES_BROWSER", "firefox")
+	t.Setenv("HTTPS_PROXY", "http://env.proxy:3128")
+	t.Setenv("YEET_YTDLP_ARGS", "--sleep-interval 5")
+	cfg.ApplyEnv()
+
+	// a hotkey changes one setting, then saves
+	cfg.Format = "mp3"
+	cfg.CookiesFromBrowser = "brave"
+	if err := cfg.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Format != "mp3" || saved.CookiesFromBrowser != "brave" {
+		t.Errorf("changed settings not saved: format %q, cookies %q", saved.Format, saved.CookiesFromBrowser)
+	}
+	if saved.RateLimit != "1M" || saved.Proxy != "" || len(saved.ExtraArgs) != 0 {
+		t.Errorf("env overrides leaked into the file: rate %q, proxy %q, extra args %q", saved.RateLimit, saved.Proxy, saved.ExtraArgs)
+	}
+	if cfg.RateLimit != "2M" || cfg.Proxy != "http://env.proxy:3128" {
+		t.Error("saving dropped the overrides from the running config")
+	}
+}
+
+func TestLoadBacksUpCorruptedFile(t *testing.T) {
+	const broken = `{"format":"mkv","max_height":`
+	path := writeConfig(t, broken)
+
+	cfg, err := Load(path)
+	var corrupt *CorruptConfigError
+	if !errors.As(err, &corrupt) {
+		t.Fatalf("Load error = %v, want a *CorruptConfigError", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("Load = %+v, want the defaults", cfg)
+	}
+	if corrupt.Backup != path+".bak" {
+		t.Errorf("Backup = %q, want %q", corrupt.Backup, path+".bak")
+	}
+	if backup, err := os.ReadFile(path + ".bak"); err != nil || string(backup) != broken {
+		t.Fatalf("backup = %q, %v, want the original contents", backup, err)
+	}
+
+	// with the original safe, a hotkey may save over it
+	if err := cfg.Save(path); err != nil {
+		t.Errorf("Save after backing up = %v", err)
+	}
+}
+
+func TestSaveKeepsCorruptedFileWithoutBackup(t *testing.T) {
+	const broken = `{"format":"mkv","max_height":`
+	path := writeConfig(t, broken)
+	// a directory where the backup should go
+	if err := os.Mkdir(path+".bak", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	var corrupt *CorruptConfigError
+	if !errors.As(err, &corrupt) || corrupt.Backup != "" {
+		t.Fatalf("Load error = %v, want a *CorruptConfigError without a backup", err)
+	}
+	cfg.Format = "mp3"
+	if err := cfg.Save(path); err == nil {
+		t.Error("Save overwrote a corrupted file that has no backup")
+	}
+	if data, _ := os.ReadFile(path); string(data) != broken {
+		t.Errorf("config file = %q, want it untouched", data)
+	}
+}
+
+func TestMaxPlaylistEntries(t *testing.T) {
+	if got := Default().MaxPlaylistEntries; got != 50 {
+		t.Errorf("default MaxPlaylistEntries = %d, want 50", got)
+	}
+	cfg, err := Load(writeConfig(t, `{"max_playlist_entries":200}`))
+	if err != nil || cfg.MaxPlaylistEntries != 200 {
+		t.Errorf("Load = %d, %v, want the file's 200", cfg.MaxPlaylistEntries, err)
+	}
+}
@@ -0,0 +1,297 @@
+package downloader
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// hasRun reports whether want appears in args as consecutive elements
+func hasRun(args, want []string) bool {
+	for i := range args {
+		if len(args)-i >= len(want) && slices.Equal(args[i:i+len(want)], want) {
+			return true
+		}
+	}
+	return false
+}
+
+const watchURL = "https://www.youtube.com/watch?v=dQw4w9WgXcQ"
+
+func TestDownloadArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		opts   Options
+		want   [][]string // each run must appear in order, back to back
+		absent []string
+	}{
+		{
+			name: "mp4 default height",
+			opts: Options{Format: "mp4"},
+			want: [][]string{
+				{"-f", "bestvideo[height<=2160]+bestaudio/best[height<=2160]/best"},
+				{"--merge-output-format", "mp4"},
+				{"--no-playlist"},
+			},
+			absent: []string{"-x", "--audio-format", "--write-subs", "--limit-rate", "--download-sections", "--proxy", "--remux-video"},
+		},
+		{
+			name: "mp4 height cap",
+			opts: Options{Format: "mp4", MaxHeight: 720},
+			want: [][]string{{"-f", "bestvideo[height<=720]+bestaudio/best[height<=720]/best"}},
+		},
+		{
+			name:   "mp4 without ffmpeg",
+			opts:   Options{Format: "mp4", MaxHeight: 480, NoFFmpeg: true},
+			want:   [][]string{{"-f", "best[height<=480]/best"}},
+			absent: []string{"--merge-output-format"},
+		},
+		{
+			name: "mkv",
+			opts: Options{Format: "mkv"},
+			want: [][]string{{"--merge-output-format", "mkv"}, {"--remux-video", "mkv"}},
+		},
+		{
+			name: "explicit format id",
+			opts: Options{Format: "mp4", FormatID: "137+140", MaxHeight: 720},
+			want: [][]string{{"-f", "137+140"}},
+		},
+		{
+			name:   "mp3",
+			opts:   Options{Format: "mp3"},
+			want:   [][]string{{"-f", "bestaudio"}, {"-x", "--audio-format", "mp3"}, {"--audio-quality", "192K"}},
+			absent: []string{"--merge-output-format"},
+		},
+		{
+			name: "mp3 at 320K",
+			opts: Options{Format: "mp3", AudioQuality: "320K"},
+			want: [][]string{{"--audio-quality", "320K"}},
+		},
+		{
+			name: "m4a at 320K",
+			opts: Options{Format: "mp3", AudioFormat: "m4a", AudioQuality: "320K"},
+			want: [][]string{{"-x", "--audio-format", "m4a"}, {"--audio-quality", "320K"}},
+		},
+		{
+			name: "opus",
+			opts: Options{Format: "mp3", AudioFormat: "opus"},
+			want: [][]string{{"-x", "--audio-format", "opus"}, {"--audio-quality", "192K"}},
+		},
+		{
+			name:   "flac ignores bitrate",
+			opts:   Options{Format: "mp3", AudioFormat: "flac", AudioQuality: "320K"},
+			want:   [][]string{{"-x", "--audio-format", "flac"}},
+			absent: []string{"--audio-quality"},
+		},
+		{
+			name:   "wav ignores bitrate",
+			opts:   Options{Format: "mp3", AudioFormat: "wav"},
+			want:   [][]string{{"-x", "--audio-format", "wav"}},
+			absent: []string{"--audio-quality"},
+		},
+		{
+			name: "unknown codec falls back to mp3",
+			opts: Options{Format: "mp3", AudioFormat: "ogg"},
+			want: [][]string{{"-x", "--audio-format", "mp3"}},
+		},
+		{
+			name: "mp3 with album art",
+			opts: Options{Format: "mp3", EmbedArt: true},
+			want: [][]string{{"--embed-thumbnail", "--embed-metadata", "--add-metadata"}},
+		},
+		{
+			name:   "mp3 without album art",
+			opts:   Options{Format: "mp3"},
+			absent: []string{"--embed-thumbnail", "--embed-metadata", "--add-metadata"},
+		},
+		{
+			name:   "wav can't carry album art",
+			opts:   Options{Format: "mp3", AudioFormat: "wav", EmbedArt: true},
+			absent: []string{"--embed-thumbnail", "--embed-metadata"},
+		},
+		{
+			name:   "album art is audio only",
+			opts:   Options{Format: "mp4", EmbedArt: true},
+			absent: []string{"--embed-thumbnail", "--embed-metadata"},
+		},
+		{
+			name: "default subtitles",
+			opts: Options{Format: "mp4", DownloadSubs: true},
+			want: [][]string{{"--write-subs", "--sub-langs", "en"}},
+		},
+		{
+			name: "subtitle languages",
+			opts: Options{Format: "mp4", DownloadSubs: true, SubLangs: []string{"en", "de"}},
+			want: [][]string{{"--write-subs", "--sub-langs", "en,de"}},
+		},
+		{
+			name: "clip range",
+			opts: Options{Format: "mp4", ClipStart: "00:01:00", ClipEnd: "00:02:30"},
+			want: [][]string{
+				{"--download-sections", "*00:01:00-00:02:30"},
+				{"-o", "%(title)s [clip 000100-000230].%(ext)s"},
+			},
+		},
+		{
+			name: "rate limit",
+			opts: Options{Format: "mp4", RateLimit: "2M"},
+			want: [][]string{{"--limit-rate", "2M"}},
+		},
+		{
+			name:   "invalid rate limit",
+			opts:   Options{Format: "mp4", RateLimit: "fast"},
+			absent: []string{"--limit-rate"},
+		},
+		{
+			name: "proxy",
+			opts: Options{Format: "mp4", Proxy: "socks5://127.0.0.1:9050"},
+			want: [][]string{{"--proxy", "socks5://127.0.0.1:9050"}},
+		},
+		{
+			name:   "invalid proxy",
+			opts:   Options{Format: "mp4", Proxy: "ftp://example.com"},
+			absent: []string{"--proxy"},
+		},
+		{
+			name:   "playlist",
+			url:    "https://www.youtube.com/playlist?list=PL123",
+			opts:   Options{Format: "mp4", PlaylistItems: "1-3"},
+			want:   [][]string{{"--playlist-items", "1-3"}},
+			absent: []string{"--no-playlist"},
+		},
+		{
+			name: "watch link from a playlist",
+			url:  watchURL + "&list=PL123&index=4",
+			opts: Options{Format: "mp4"},
+			want: [][]string{{"--no-playlist"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := tt.url
+			if url == "" {
+				url = watchURL
+			}
+			args := downloadArgs(url, tt.opts)
+			for _, want := range tt.want {
+				if !hasRun(args, want) {
+					t.Errorf("args %q lack %q", args, want)
+				}
+			}
+			for _, flag := range tt.absent {
+				if slices.Contains(args, flag) {
+					t.Errorf("args %q contain %s", args, flag)
+				}
+			}
+			if args[len(args)-1] != url {
+				t.Errorf("args end in %q, want the URL", args[len(args)-1])
+			}
+		})
+	}
+}
+
+func TestDownloadArgsExtraArgsComeLast(t *testing.T) {
+	extra := []string{"--limit-rate", "500K", "--embed-chapters"}
+	args := downloadArgs(watchURL, Options{Format: "mp4", RateLimit: "2M", ExtraArgs: extra})
+
+	tail := args[len(args)-len(extra)-1 : len(args)-1]
+	if !slices.Equal(tail, extra) {
+		t.Errorf("args before the URL = %q, want the extra args %q in order", tail, extra)
+	}
+	if i := slices.Index(args, "500K"); i < slices.Index(args, "2M") {
+		t.Error("user rate limit comes before the built-in one, so it wouldn't win")
+	}
+}
+
+func TestFormatSelectorMatchesDownloadArgs(t *testing.T) {
+	for _, opts := range []Options{
+		{Format: "mp4"},
+		{Format: "mp4", MaxHeight: 1080},
+		{Format: "mkv", MaxHeight: 1440},
+		{Format: "mp4", NoFFmpeg: true},
+		{Format: "mp3", AudioFormat: "flac"},
+		{Format: "mp4", FormatID: "22"},
+	} {
+		args := downloadArgs(watchURL, opts)
+		if !hasRun(args, []string{"-f", formatSelector(opts)}) {
+			t.Errorf("%+v: args %q don't use formatSelector's %q", opts, args, formatSelector(opts))
+		}
+	}
+}
+
+func TestOutputTemplate(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"default", Options{}, "%(title)s.%(ext)s"},
+		{"custom", Options{OutputTemplate: "%(uploader)s/%(title)s.%(ext)s"}, "%(uploader)s/%(title)s.%(ext)s"},
+		{"invalid custom", Options{OutputTemplate: "video.mp4"}, "%(title)s.%(ext)s"},
+		{"clip", Options{ClipStart: "00:00:10", ClipEnd: "00:01:00"}, "%(title)s [clip 000010-000100].%(ext)s"},
+		{"unique", Options{UniqueName: true}, "%(title)s [%(id)s].%(ext)s"},
+		{"unique clip", Options{UniqueName: true, ClipStart: "00:00:10", ClipEnd: "00:01:00"}, "%(title)s [clip 000010-000100] [%(id)s].%(ext)s"},
+		{"unique with id already", Options{UniqueName: true, OutputTemplate: "%(id)s.%(ext)s"}, "%(id)s.%(ext)s"},
+		{"unique without extension", Options{UniqueName: true, OutputTemplate: "%(title)s"}, "%(title)s [%(id)s]"},
+	}
+
+	for _, tt := range tests {
+		if got := OutputTemplate(tt.opts); got != tt.want {
+			t.Errorf("%s: OutputTemplate = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestChapterTemplateGetsTheID(t *testing.T) {
+	args := downloadArgs(watchURL, Options{Format: "mp4", SplitChapters: true, UniqueName: true})
+	want := "chapter:%(title)s - %(section_number)03d %(section_title)s [%(id)s].%(ext)s"
+	if !hasRun(args, []string{"--split-chapters", "-o", want}) {
+		t.Errorf("args %q lack the id-suffixed chapter template", args)
+	}
+}
+
+func TestAudioOnly(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		abr  float64
+	}{
+		{"default bitrate", Options{Format: "mp3"}, 192},
+		{"chosen bitrate", Options{Format: "mp3", AudioFormat: "opus", AudioQuality: "128K"}, 128},
+		{"lossless keeps the source bitrate", Options{Format: "mp3", AudioFormat: "flac", AudioQuality: "320K"}, 160},
+	}
+
+	for _, tt := range tests {
+		info := VideoInfo{Resolution: "1920x1080", Width: 1920, Height: 1080, FPS: 60, VBR: 4000, ABR: 160, TBR: 4160}
+		audioOnly(&info, tt.opts)
+
+		want := VideoInfo{Resolution: "audio only", ABR: tt.abr, TBR: tt.abr}
+		if !reflect.DeepEqual(info, want) {
+			t.Errorf("%s: audioOnly = %+v, want %+v", tt.name, info, want)
+		}
+	}
+}
+
+func TestMkvRemuxesWithoutRecoding(t *testing.T) {
+	for _, opts := range []Options{
+		{Format: "mkv"},
+		{Format: "mkv", MaxHeight: 720},
+		{Format: "mkv", FormatID: "22"}, // a single-file format is copied too
+	} {
+		args := downloadArgs(watchURL, opts)
+		if !hasRun(args, []string{"--remux-video", "mkv"}) {
+			t.Errorf("%+v: args %q don't remux into mkv", opts, args)
+		}
+		if slices.Contains(args, "--recode-video") {
+			t.Errorf("%+v: args %q re-encode", opts, args)
+		}
+	}
+
+	// without ffmpeg there's nothing to remux with
+	args := downloadArgs(watchURL, Options{Format: "mkv", NoFFmpeg: true})
+	if slices.Contains(args, "--remux-video") {
+		t.Errorf("args %q remux without ffmpeg", args)
+	}
+}
@@ -0,0 +1,45 @@
+package downloader
+
+import (
+	"io"
+	"log"
+	"os"
+	"sync"
+)
+
+var (
+	debugMu  sync.Mutex
+	debugLog *log.Logger // nil unless EnableDebugLog was called
+)
+
+// EnableDebugLog tees every downloader callback line to the file at path
+func EnableDebugLog(path string) (io.Closer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	debugMu.Lock()
+	debugLog = log.New(f, "", log.LstdFlags|log.Lmicroseconds)
+	debugMu.Unlock()
+	return f, nil
+}
+
+// debugf writes to the debug log when it is enabled
+func debugf(format string, args ...interface{}) {
+	debugMu.Lock()
+	defer debugMu.Unlock()
+	if debugLog != nil {
+		debugLog.Printf(format, args...)
+	}
+}
+
+// withDebugLog wraps callback so each line is also written to the debug log
+func withDebugLog(url string, callback ProgressCallback) ProgressCallback {
+	return func(fraction float64, line string) {
+		if line != "" {
+			debugf("[%s] %s", url, line)
+		}
+		callback(fraction, line)
+	}
+}
@@ -0,0 +1,78 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// MissingDependenciesError lists the external tools that couldn't be found on PATH
+type MissingDependenciesError struct {
+	Missing []string
+}
+
+func (e *MissingDependenciesError) Error() string {
+	return "missing dependencies: " + strings.Join(e.Missing, ", ")
+}
+
+// Has reports whether tool is among the missing dependencies
+func (e *MissingDependenciesError) Has(tool string) bool {
+	for _, m := range e.Missing {
+		if m == tool {
+			return true
+		}
+	}
+	return false
+}
+
+// ytdlpPath is the yt-dlp binary every command runs
+var ytdlpPath = "yt-dlp"
+
+// SetYtDlpPath points the downloader at a specific yt-dlp binary (a name
+// looked up on PATH, or a path), failing unless it is executable. Call it
+// before starting any downloads.
+func SetYtDlpPath(path string) error {
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return fmt.Errorf("yt-dlp binary %q: %w", path, err)
+	}
+	ytdlpPath = resolved
+	return nil
+}
+
+// CheckDependencies verifies yt-dlp and ffmpeg (needed for merging and mp3) are installed
+func CheckDependencies() error {
+	var missing []string
+	binaries := map[string]string{"yt-dlp": ytdlpPath, "ffmpeg": "ffmpeg"}
+	for _, tool := range []string{"yt-dlp", "ffmpeg"} {
+		if _, err := exec.LookPath(binaries[tool]); err != nil {
+			missing = append(missing, tool)
+		}
+	}
+
+	if len(missing) > 0 {
+		return &MissingDependenciesError{Missing: missing}
+	}
+	return nil
+}
+
+// FFmpegVersion returns the version reported by "ffmpeg -version", e.g. "6.1.1"
+func FFmpegVersion() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	out, err := runOutput(ctx, "ffmpeg", "-version")
+	if err != nil {
+		return "", err
+	}
+
+	// First line looks like "ffmpeg version 6.1.1 Copyright (c) 2000-2023 ..."
+	firstLine := strings.SplitN(string(out), "\n", 2)[0]
+	fields := strings.Fields(firstLine)
+	if len(fields) < 3 || fields[1] != "version" {
+		return "", fmt.Errorf("unexpected ffmpeg output: %q", firstLine)
+	}
+	return fields[2], nil
+}
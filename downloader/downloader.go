@@ -2,13 +2,17 @@ package downloader
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -19,13 +23,22 @@ import (
 type ProgressFractionMsg struct {
 	Fraction float64
 	Line     string
+	Speed    string // e.g. "1.20MiB/s", empty when unknown
+	ETA      string // e.g. "00:05", empty when unknown
+	Total    int64  // total bytes of the current file, 0 when unknown
+
+	// Done marks the last message of a download; the channel is closed
+	// right after it. Err is set when the download failed or was cancelled.
+	Done bool
+	Err  error
 }
 
 // TitleFetchedMsg is sent when title is fetched
 type TitleFetchedMsg struct {
-	URL   string
-	Title string
-	Error error
+	URL        string
+	Title      string
+	LiveStatus string // yt-dlp's live_status, e.g. LiveStatusLive; empty when not reported
+	Error      error
 }
 
 // ProgressCallback is called with (fraction, logLine)
@@ -36,97 +49,625 @@ type TitleCallback func(TitleFetchedMsg)
 
 // VideoInfo represents saved metadata
 type VideoInfo struct {
-	URL          string    `json:"url"`
-	Title        string    `json:"title"`
-	Duration     float64   `json:"duration"`
-	Resolution   string    `json:"resolution"`
-	Width        int       `json:"width"`
-	Height       int       `json:"height"`
-	FPS          int       `json:"fps"`
-	VBR          float64   `json:"video_bitrate_kbps"`
-	ABR          float64   `json:"audio_bitrate_kbps"`
-	TBR          float64   `json:"total_bitrate_kbps"`
-	Filesize     int64     `json:"filesize"`
-	DownloadedAt time.Time `json:"downloaded_at"`
-}
-
-// DownloadStreamWithProgress streams video download progress via callback
-func DownloadStreamWithProgress(url string, format string, callback ProgressCallback) {
-	go func() {
-		var cmd *exec.Cmd
-		if format == "mp3" {
-			cmd = exec.Command(
-				"yt-dlp",
-				"-f", "bestaudio",
-				"--extract-audio",
-				"--audio-format", "mp3",
-				"-o", "%(title)s.%(ext)s",
-				"--no-check-certificate",
-				"--add-header", "User-Agent: Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/58.0.3029.110 Safari/537.36",
-				"--newline",
-				url,
-			)
-		} else {
-			cmd = exec.Command(
-				"yt-dlp",
-				"-f", "bestvideo[height<=2160]+bestaudio/best",
-				"--merge-output-format", "mp4",
-				"-o", "%(title)s.%(ext)s",
-				"--no-check-certificate",
-				"--add-header", "User-Agent: Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/58.0.3029.110 Safari/537.36",
-				"--newline",
-				url,
-			)
+	URL           string    `json:"url"`
+	Title         string    `json:"title"`
+	Duration      float64   `json:"duration"`
+	Resolution    string    `json:"resolution"`
+	Width         int       `json:"width"`
+	Height        int       `json:"height"`
+	FPS           int       `json:"fps"`
+	VBR           float64   `json:"video_bitrate_kbps"`
+	ABR           float64   `json:"audio_bitrate_kbps"`
+	TBR           float64   `json:"total_bitrate_kbps"`
+	Filesize      int64     `json:"filesize"`
+	Extractor     string    `json:"extractor,omitempty"`   // source site, e.g. "Youtube", "Vimeo"
+	Channel       string    `json:"channel,omitempty"`     // uploader or channel name
+	WebpageURL    string    `json:"webpage_url,omitempty"` // canonical page URL reported by yt-dlp
+	HasSubtitles  bool      `json:"has_subtitles,omitempty"`
+	ThumbnailPath string    `json:"thumbnail_path,omitempty"`
+	Format        string    `json:"format,omitempty"`       // one of Formats as archived, empty in older records
+	EmbeddedArt   bool      `json:"embedded_art,omitempty"` // mp3 carries album art and tags
+	ClipStart     string    `json:"clip_start,omitempty"`   // set when only a section was archived
+	ClipEnd       string    `json:"clip_end,omitempty"`
+	FilePath      string    `json:"file_path,omitempty"`     // absolute path of the archived media
+	UniqueName    bool      `json:"unique_name,omitempty"`   // FilePath carries the video id after a name collision
+	Tags          []string  `json:"tags,omitempty"`          // user categories, lowercase
+	IsFavorite    bool      `json:"is_favorite,omitempty"`   // pinned to the top of the history list
+	WatchUpdates  bool      `json:"watch_updates,omitempty"` // checked with CheckForUpdate at startup
+	LiveStatus    string    `json:"live_status,omitempty"`   // yt-dlp's live_status, e.g. "was_live"
+	ChapterFiles  []string  `json:"chapter_files,omitempty"` // one file per chapter when SplitChapters split it
+	Sidecars      []string  `json:"sidecars,omitempty"`      // .info.json and .description saved by WriteMetadata
+	Container     string    `json:"container,omitempty"`     // extension of the archived file, e.g. "mkv"
+	AudioFormat   string    `json:"audio_format,omitempty"`  // codec of an audio extraction, e.g. "flac"
+	DownloadedAt  time.Time `json:"downloaded_at"`
+}
+
+// userAgent is sent with every yt-dlp request
+const userAgent = "User-Agent: Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/58.0.3029.110 Safari/537.36"
+
+// Options controls how a single case is downloaded
+type Options struct {
+	Format    string `json:"format"`               // one of Formats; "mp3" is audio extraction in AudioFormat
+	FormatID  string `json:"format_id,omitempty"`  // exact -f selector picked from ListFormats, overrides MaxHeight
+	MaxHeight int    `json:"max_height,omitempty"` // video resolution cap, 0 means 2160
+
+	AudioFormat  string `json:"audio_format,omitempty"`  // audio only: one of AudioFormats, defaults to mp3
+	AudioQuality string `json:"audio_quality,omitempty"` // audio only: lossy bitrate, one of AudioQualities
+	EmbedArt     bool   `json:"embed_art,omitempty"`     // audio only: embed the thumbnail and tags in the file
+
+	DownloadSubs bool     `json:"download_subs,omitempty"` // also fetch subtitles
+	SubLangs     []string `json:"sub_langs,omitempty"`     // subtitle languages, defaults to "en"
+
+	WriteThumbnail bool `json:"write_thumbnail,omitempty"` // save the thumbnail as a .jpg next to the media
+	WriteMetadata  bool `json:"write_metadata,omitempty"`  // save yt-dlp's .info.json and the .description next to the media
+
+	HistoryPath string `json:"history_path,omitempty"` // metadata file, defaults to DefaultHistoryPath
+	MaxHistory  int    `json:"max_history,omitempty"`  // keep only the newest N records, 0 means unlimited
+
+	CookiesFromBrowser string `json:"cookies_from_browser,omitempty"` // e.g. "firefox", passed to --cookies-from-browser
+
+	NoFFmpeg bool `json:"no_ffmpeg,omitempty"` // ffmpeg is unavailable: fetch a single pre-merged file
+
+	OutputTemplate string `json:"output_template,omitempty"` // yt-dlp -o template, defaults to DefaultOutputTemplate
+	OutputDir      string `json:"output_dir,omitempty"`      // passed to -P, empty means the working directory
+	UniqueName     bool   `json:"unique_name,omitempty"`     // add the video id to file names, set after a name collision
+
+	TitleTimeout time.Duration `json:"title_timeout,omitempty"` // limit for FetchTitleAsync, defaults to DefaultTitleTimeout
+
+	RateLimit string `json:"rate_limit,omitempty"` // bandwidth cap for --limit-rate, e.g. "500K"; ignored unless ValidRateLimit
+
+	Proxy string `json:"proxy,omitempty"` // passed to --proxy for every yt-dlp call; ignored unless ValidProxy
+
+	Resume bool `json:"resume,omitempty"` // pick up existing .part files instead of starting over
+
+	ConcurrentFragments int `json:"concurrent_fragments,omitempty"` // parallel DASH/HLS fragment downloads, 1..MaxConcurrentFragments
+
+	ClipStart string `json:"clip_start,omitempty"` // HH:MM:SS, only fetch this section when ClipEnd is set too
+	ClipEnd   string `json:"clip_end,omitempty"`
+
+	Overwrite bool `json:"overwrite,omitempty"` // re-download over an existing file and replace its history record
+
+	LiveFromStart bool `json:"live_from_start,omitempty"` // record a live stream from its beginning
+
+	SplitChapters bool `json:"split_chapters,omitempty"` // also save each chapter as its own file, needs ffmpeg
+
+	ExtraArgs []string `json:"extra_args,omitempty"` // user flags placed after the built-in ones, so they override them
+
+	PlaylistItems string `json:"playlist_items,omitempty"` // playlist URLs only: videos to fetch, e.g. "1-10,15"; see ValidatePlaylistItems
+
+	// Pool, when set, holds a slot already claimed for this download;
+	// it is released once the download finishes.
+	Pool *Pool `json:"-"`
+
+	// Process, when set, is kept pointing at the running yt-dlp attempt
+	// so the caller can pause it with PauseDownload.
+	Process *Process `json:"-"`
+}
+
+// downloadArgs builds the yt-dlp arguments for the given options
+func downloadArgs(url string, opts Options) []string {
+	args := []string{"-f", formatSelector(opts)}
+	if opts.Format == "mp3" {
+		args = append(args, "-x", "--audio-format", audioFormat(opts))
+		if !LosslessAudio(audioFormat(opts)) {
+			args = append(args, "--audio-quality", audioQuality(opts))
 		}
+		if opts.embedsArt() {
+			args = append(args, "--embed-thumbnail", "--embed-metadata", "--add-metadata")
+		}
+	} else if !opts.NoFFmpeg {
+		args = append(args, "--merge-output-format", container(opts))
+	}
+	if opts.Format == "mkv" && !opts.NoFFmpeg {
+		// single-file formats are copied into mkv as well, never re-encoded
+		args = append(args, "--remux-video", "mkv")
+	}
 
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			callback(0, "❌ Error creating stderr pipe: "+err.Error())
-			return
+	if opts.DownloadSubs {
+		args = append(args, "--write-subs", "--sub-langs", strings.Join(subLangs(opts), ","))
+	}
+
+	if opts.WriteThumbnail && !opts.NoFFmpeg {
+		args = append(args, "--write-thumbnail", "--convert-thumbnails", "jpg")
+	}
+	if opts.WriteMetadata {
+		// sidecars follow the -o template and -P directory like the media
+		args = append(args, "--write-info-json", "--write-description")
+	}
+	args = append(args, requestArgs(opts)...)
+
+	if ValidRateLimit(opts.RateLimit) {
+		args = append(args, "--limit-rate", opts.RateLimit)
+	}
+	if opts.Resume {
+		args = append(args, "--continue", "--part")
+	}
+	if !IsPlaylist(url) {
+		// a watch link opened from a playlist would otherwise fetch the whole list
+		args = append(args, "--no-playlist")
+	} else if ValidatePlaylistItems(opts.PlaylistItems) == nil {
+		args = append(args, "--playlist-items", strings.ReplaceAll(opts.PlaylistItems, " ", ""))
+	}
+	if n := opts.ConcurrentFragments; n > 1 && ValidConcurrentFragments(n) {
+		args = append(args, "--concurrent-fragments", strconv.Itoa(n))
+	}
+	if opts.isClip() {
+		args = append(args, "--download-sections", "*"+opts.ClipStart+"-"+opts.ClipEnd)
+	}
+	if opts.OutputDir != "" {
+		args = append(args, "-P", opts.OutputDir)
+	}
+	if opts.Overwrite {
+		args = append(args, "--force-overwrites")
+	}
+	if opts.LiveFromStart {
+		args = append(args, "--live-from-start")
+	}
+	if opts.SplitChapters && !opts.NoFFmpeg {
+		args = append(args, "--split-chapters", "-o", "chapter:"+withID(ChapterTemplate, opts))
+	}
+
+	args = append(args,
+		"-o", OutputTemplate(opts),
+		"--no-check-certificate",
+		"--add-header", userAgent,
+		"--newline",
+		"--progress-template", postprocessTemplate,
+	)
+	// yt-dlp lets later flags win, so user flags go after the built-in ones
+	args = append(args, opts.ExtraArgs...)
+	return append(args, url)
+}
+
+// Formats are the values accepted for Options.Format. "mkv" is remux mode:
+// streams are copied into an mkv, which takes any codec, so nothing is re-encoded.
+var Formats = []string{"mp4", "mkv", "mp3"}
+
+// ValidFormat reports whether f is one of Formats
+func ValidFormat(f string) bool {
+	return slices.Contains(Formats, f)
+}
+
+// container returns the video container to merge into for opts
+func container(opts Options) string {
+	if opts.Format == "mkv" {
+		return "mkv"
+	}
+	return "mp4"
+}
+
+// AudioFormats are the codecs accepted for Options.AudioFormat
+var AudioFormats = []string{"mp3", "m4a", "opus", "flac", "wav"}
+
+// DefaultAudioFormat is used when no valid audio format is configured
+const DefaultAudioFormat = "mp3"
+
+// ValidAudioFormat reports whether f is one of AudioFormats
+func ValidAudioFormat(f string) bool {
+	return slices.Contains(AudioFormats, f)
+}
+
+// LosslessAudio reports whether audio format f ignores the bitrate
+func LosslessAudio(f string) bool {
+	return f == "flac" || f == "wav"
+}
+
+// audioFormat returns the configured codec, or the default if it isn't allowed
+func audioFormat(opts Options) string {
+	if ValidAudioFormat(opts.AudioFormat) {
+		return opts.AudioFormat
+	}
+	return DefaultAudioFormat
+}
+
+// embedsArt reports whether album art and tags go into the file; wav
+// can't carry a thumbnail
+func (o Options) embedsArt() bool {
+	return o.Format == "mp3" && o.EmbedArt && audioFormat(o) != "wav"
+}
+
+// AudioQualities are the lossy audio bitrates accepted for Options.AudioQuality
+var AudioQualities = []string{"128K", "192K", "256K", "320K"}
+
+// DefaultAudioQuality is used when no valid quality is configured
+const DefaultAudioQuality = "192K"
+
+// ValidAudioQuality reports whether q is one of AudioQualities
+func ValidAudioQuality(q string) bool {
+	for _, allowed := range AudioQualities {
+		if q == allowed {
+			return true
 		}
+	}
+	return false
+}
 
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			callback(0, "❌ Error creating stdout pipe: "+err.Error())
-			return
+// audioQuality returns the configured bitrate, or the default if it isn't allowed
+func audioQuality(opts Options) string {
+	if ValidAudioQuality(opts.AudioQuality) {
+		return opts.AudioQuality
+	}
+	return DefaultAudioQuality
+}
+
+// DefaultOutputTemplate names files after the video title
+const DefaultOutputTemplate = "%(title)s.%(ext)s"
+
+// templateTokenRegex matches yt-dlp output fields such as %(id)s or %(upload_date)s
+var templateTokenRegex = regexp.MustCompile(`%\([a-z0-9_.]+\)s`)
+
+// ValidOutputTemplate reports whether t contains at least one %(...)s field
+func ValidOutputTemplate(t string) bool {
+	return templateTokenRegex.MatchString(t)
+}
+
+// OutputTemplate returns the effective -o template for opts, falling back to
+// DefaultOutputTemplate when the configured one is missing or invalid
+func OutputTemplate(opts Options) string {
+	tmpl := DefaultOutputTemplate
+	if ValidOutputTemplate(opts.OutputTemplate) {
+		tmpl = opts.OutputTemplate
+	}
+
+	// Keep clips from overwriting a full archive of the same video
+	if opts.isClip() && strings.HasSuffix(tmpl, ".%(ext)s") {
+		clip := strings.ReplaceAll(opts.ClipStart+"-"+opts.ClipEnd, ":", "")
+		tmpl = strings.TrimSuffix(tmpl, ".%(ext)s") + " [clip " + clip + "].%(ext)s"
+	}
+	return withID(tmpl, opts)
+}
+
+// withID adds the video id to tmpl when opts.UniqueName asks for it and
+// the template doesn't already tell videos apart by id
+func withID(tmpl string, opts Options) string {
+	if !opts.UniqueName || strings.Contains(tmpl, "%(id)s") {
+		return tmpl
+	}
+	if strings.HasSuffix(tmpl, ".%(ext)s") {
+		return strings.TrimSuffix(tmpl, ".%(ext)s") + " [%(id)s].%(ext)s"
+	}
+	return tmpl + " [%(id)s]"
+}
+
+// ChapterTemplate names the files --split-chapters writes next to the full archive
+const ChapterTemplate = "%(title)s - %(section_number)03d %(section_title)s.%(ext)s"
+
+// clipTimestampRegex matches an HH:MM:SS clip boundary
+var clipTimestampRegex = regexp.MustCompile(`^(\d{2}):([0-5]\d):([0-5]\d)$`)
+
+// clipSeconds converts an HH:MM:SS timestamp to seconds, or -1 if it is malformed
+func clipSeconds(ts string) int {
+	m := clipTimestampRegex.FindStringSubmatch(ts)
+	if m == nil {
+		return -1
+	}
+	h, _ := strconv.Atoi(m[1])
+	mins, _ := strconv.Atoi(m[2])
+	sec, _ := strconv.Atoi(m[3])
+	return h*3600 + mins*60 + sec
+}
+
+// ValidateClip checks that start and end are HH:MM:SS timestamps with start before end
+func ValidateClip(start, end string) error {
+	s, e := clipSeconds(start), clipSeconds(end)
+	switch {
+	case s < 0:
+		return fmt.Errorf("clip start %q is not HH:MM:SS", start)
+	case e < 0:
+		return fmt.Errorf("clip end %q is not HH:MM:SS", end)
+	case s >= e:
+		return fmt.Errorf("clip start %s must be before end %s", start, end)
+	}
+	return nil
+}
+
+// isClip reports whether opts asks for a valid section only
+func (o Options) isClip() bool {
+	return o.ClipStart != "" && o.ClipEnd != "" && ValidateClip(o.ClipStart, o.ClipEnd) == nil
+}
+
+// rateLimitRegex matches a byte rate with an optional K/M suffix, e.g. "500K" or "2M"
+var rateLimitRegex = regexp.MustCompile(`^\d+(\.\d+)?[KkMm]?$`)
+
+// ValidRateLimit reports whether r can be passed to yt-dlp's --limit-rate
+func ValidRateLimit(r string) bool {
+	return rateLimitRegex.MatchString(r)
+}
+
+// MaxConcurrentFragments caps Options.ConcurrentFragments
+const MaxConcurrentFragments = 16
+
+// ValidConcurrentFragments reports whether n is an accepted fragment count
+func ValidConcurrentFragments(n int) bool {
+	return n >= 1 && n <= MaxConcurrentFragments
+}
+
+// DefaultHistoryPath is where archive metadata is stored unless configured otherwise
+const DefaultHistoryPath = "downloads.json"
+
+// historyPath returns the configured history file, or the default
+func (o Options) historyPath() string {
+	if o.HistoryPath == "" {
+		return DefaultHistoryPath
+	}
+	return o.HistoryPath
+}
+
+// subLangs returns the requested subtitle languages, defaulting to English
+func subLangs(opts Options) []string {
+	if len(opts.SubLangs) == 0 {
+		return []string{"en"}
+	}
+	return opts.SubLangs
+}
+
+// requestArgs returns the yt-dlp flags every request needs: browser
+// cookies for authentication and the proxy to route through
+func requestArgs(opts Options) []string {
+	var args []string
+	if opts.CookiesFromBrowser != "" {
+		args = append(args, "--cookies-from-browser", opts.CookiesFromBrowser)
+	}
+	if ValidProxy(opts.Proxy) {
+		args = append(args, "--proxy", opts.Proxy)
+	}
+	return args
+}
+
+// proxySchemes are the proxy protocols yt-dlp understands
+var proxySchemes = []string{"http", "https", "socks4", "socks4a", "socks5", "socks5h"}
+
+// ValidProxy reports whether p is a proxy URL yt-dlp's --proxy accepts,
+// e.g. "http://proxy.corp:3128" or "socks5://127.0.0.1:1080"
+func ValidProxy(p string) bool {
+	u, err := url.Parse(p)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return slices.Contains(proxySchemes, strings.ToLower(u.Scheme))
+}
+
+// authErrorPhrases appear in yt-dlp errors that cookies would fix
+var authErrorPhrases = []string{
+	"sign in to confirm",
+	"login required",
+	"members-only",
+	"private video",
+	"use --cookies",
+	"requires authentication",
+}
+
+// IsAuthError reports whether a failure reason looks like missing authentication
+func IsAuthError(reason string) bool {
+	reason = strings.ToLower(reason)
+	for _, phrase := range authErrorPhrases {
+		if strings.Contains(reason, phrase) {
+			return true
 		}
+	}
+	return false
+}
+
+// FailurePrefix starts the log line sent when a download gives up
+const FailurePrefix = "❌ Download failed: "
 
-		if err := cmd.Start(); err != nil {
-			callback(0, "❌ Error starting download: "+err.Error())
+// RetryPolicy controls how failed downloads are retried
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration // doubled after every attempt
+}
+
+// DefaultRetryPolicy is used by DownloadStreamWithProgress
+var DefaultRetryPolicy = RetryPolicy{MaxRetries: 3, BaseDelay: 2 * time.Second}
+
+// DownloadStreamWithProgress downloads url in the background, streaming
+// progress on ch. The final message has Done set and ch is closed after it.
+// ch must be buffered: progress is dropped rather than blocking when it is
+// nearly full, and one slot is always left free for the final message.
+// Cancelling ctx kills the yt-dlp process.
+func DownloadStreamWithProgress(ctx context.Context, url string, opts Options, ch chan<- ProgressFractionMsg) {
+	var sendMu sync.Mutex // stdout and stderr are read concurrently
+	callback := withDebugLog(url, func(fraction float64, line string) {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		if len(ch) >= cap(ch)-1 {
 			return
 		}
+		speed, eta := ParseProgressDetails(line)
+		ch <- ProgressFractionMsg{
+			Fraction: fraction,
+			Line:     line,
+			Speed:    speed,
+			ETA:      eta,
+			Total:    ParseTotalBytes(line),
+		}
+	})
 
-		var wg sync.WaitGroup
-		wg.Add(2)
+	go func() {
+		if opts.Pool != nil {
+			defer opts.Pool.Release()
+		}
+
+		policy := DefaultRetryPolicy
+		filePath, chapters, err := runDownload(ctx, url, opts, callback)
+		if formatUnavailable(err) && opts.FormatID != fallbackFormat {
+			callback(-1, "↻ REQUESTED FORMAT UNAVAILABLE • RETRYING WITH BEST")
+			opts.FormatID = fallbackFormat
+			filePath, chapters, err = runDownload(ctx, url, opts, callback)
+		}
 
-		go func() {
-			defer wg.Done()
-			readOutput(stderr, callback, "stderr")
-		}()
+		// yt-dlp skips a file that already exists, so a different video
+		// with the same title would silently share another case's archive
+		if err == nil && !opts.UniqueName {
+			if other, ok := claimedBy(opts.historyPath(), url, filePath); ok {
+				callback(-1, "⚠ FILE NAME TAKEN BY "+strings.ToUpper(other.Title)+" • RETRYING WITH THE VIDEO ID")
+				opts.UniqueName = true
+				filePath, chapters, err = runDownload(ctx, url, opts, callback)
+			}
+		}
 
-		go func() {
-			defer wg.Done()
-			readOutput(stdout, callback, "stdout")
-		}()
+		for attempt := 1; attempt <= policy.MaxRetries && isTransient(ctx, err); attempt++ {
+			delay := policy.BaseDelay << (attempt - 1)
+			callback(-1, fmt.Sprintf("↻ RETRY %d/%d IN %s", attempt, policy.MaxRetries, delay))
 
-		wg.Wait()
+			select {
+			case <-ctx.Done():
+			case <-time.After(delay):
+				// keep whatever the failed attempt already fetched
+				opts.Resume = true
+				filePath, chapters, err = runDownload(ctx, url, opts, callback)
+			}
+		}
 
-		if err := cmd.Wait(); err != nil {
-			callback(1.0, "❌ Download failed: "+err.Error())
+		final := ProgressFractionMsg{Fraction: 1.0, Done: true}
+		if ctx.Err() != nil {
+			callback(-1, "⛔ VARIANT PURGE ABORTED")
+			final.Fraction, final.Err = -1, ctx.Err()
+		} else if err != nil {
+			callback(1.0, FailurePrefix+err.Error())
+			final.Err = err
 		} else {
 			callback(1.0, "✅ Variant pruned - Timeline restored!")
+			if opts.SplitChapters && !opts.NoFFmpeg && len(chapters) == 0 {
+				callback(1.0, "⚠ NO CHAPTER METADATA • ARCHIVED AS A SINGLE FILE")
+			}
 
 			// ✅ Save metadata after successful download
-			saveVideoInfo(url, "downloads.json")
+			saveVideoInfo(url, opts, filePath, chapters, opts.historyPath())
+		}
+
+		// the callback never fills the last slot, so this can't block
+		ch <- final
+		close(ch)
+	}()
+}
+
+// formatSelector returns the -f value a download with opts uses, so its
+// metadata can be looked up for the same streams
+func formatSelector(opts Options) string {
+	maxHeight := opts.MaxHeight
+	if maxHeight <= 0 {
+		maxHeight = 2160
+	}
+
+	switch {
+	case opts.FormatID != "":
+		return opts.FormatID
+	case opts.Format == "mp3":
+		return "bestaudio"
+	case opts.NoFFmpeg:
+		return fmt.Sprintf("best[height<=%d]/best", maxHeight)
+	default:
+		return fmt.Sprintf("bestvideo[height<=%d]+bestaudio/best[height<=%d]/best", maxHeight, maxHeight)
+	}
+}
+
+// fallbackFormat is the -f selector tried once when yt-dlp can't satisfy
+// the requested one; any single file the site offers will do
+const fallbackFormat = "best"
+
+// formatUnavailable reports whether an attempt failed on format selection
+func formatUnavailable(err error) bool {
+	var dlErr *DownloadError
+	return errors.As(err, &dlErr) && dlErr.Reason == "FORMAT UNAVAILABLE"
+}
+
+// runDownload runs a single yt-dlp attempt and waits for it to exit,
+// returning the path of the file yt-dlp finally wrote and any chapter
+// files split from it
+func runDownload(ctx context.Context, url string, opts Options, callback ProgressCallback) (string, []string, error) {
+	stdout, stderr, wait, err := Runner.Run(withProcess(ctx, opts.Process), ytdlpPath, downloadArgs(url, opts)...)
+	if err != nil {
+		return "", nil, fmt.Errorf("error starting download: %w", err)
+	}
+
+	// Remember yt-dlp's last ERROR line so failures carry a real reason,
+	// and the last output path so history knows where the file landed
+	var mu sync.Mutex
+	lastError := ""
+	filePath := ""
+	var chapters []string
+	track := func(fraction float64, line string) {
+		mu.Lock()
+		if strings.HasPrefix(line, "ERROR:") {
+			lastError = strings.TrimSpace(strings.TrimPrefix(line, "ERROR:"))
 		}
+		if m := chapterPathRegex.FindStringSubmatch(line); m != nil {
+			chapters = append(chapters, m[1])
+		} else if path := ParseOutputPath(line); path != "" {
+			filePath = path
+		}
+		mu.Unlock()
+		callback(fraction, line)
+	}
+
+	stages := &postprocessTracker{expected: postprocessStages(opts)}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
 
-		// ✅ Final step: tell caller to close channel
-		callback(1.0, "")
+	go func() {
+		defer wg.Done()
+		readOutput(stderr, track, stages.parse, "stderr")
+	}()
+
+	go func() {
+		defer wg.Done()
+		readOutput(stdout, track, stages.parse, "stdout")
 	}()
+
+	wg.Wait()
+
+	if err := wait(); err != nil {
+		if lastError != "" {
+			return "", nil, newDownloadError(lastError, err)
+		}
+		return "", nil, err
+	}
+	return filePath, chapters, nil
+}
+
+// outputPathRegexes match yt-dlp lines naming the file being written. Later
+// stages (merging, audio extraction, moving) print after the initial download,
+// so the last match is the final file.
+var outputPathRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`^\[download\] Destination: (.+)$`),
+	regexp.MustCompile(`^\[download\] (.+) has already been downloaded`),
+	regexp.MustCompile(`^\[Merger\] Merging formats into "(.+)"$`),
+	regexp.MustCompile(`^\[ExtractAudio\] Destination: (.+)$`),
+	regexp.MustCompile(`^\[MoveFiles\] Moving file ".+" to "(.+)"$`),
+}
+
+// chapterPathRegex matches the file --split-chapters writes for one chapter
+var chapterPathRegex = regexp.MustCompile(`^\[SplitChapters\] Chapter \d+; Destination: (.+)$`)
+
+// ParseOutputPath returns the media file named in a yt-dlp output line, or ""
+func ParseOutputPath(line string) string {
+	for _, re := range outputPathRegexes {
+		if matches := re.FindStringSubmatch(line); len(matches) > 1 {
+			return matches[1]
+		}
+	}
+	return ""
+}
+
+// isTransient reports whether a failed attempt is worth retrying.
+// Only non-zero yt-dlp exits count; cancellation, setup errors and
+// permanent DownloadErrors do not.
+func isTransient(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var dlErr *DownloadError
+	if errors.As(err, &dlErr) && dlErr.Permanent {
+		return false
+	}
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr)
 }
 
 // readOutput reads from a pipe and processes the output
-func readOutput(r io.Reader, callback ProgressCallback, source string) {
+func readOutput(r io.Reader, callback ProgressCallback, parse func(string) float64, source string) {
 	scanner := bufio.NewScanner(r)
 
 	for scanner.Scan() {
@@ -136,7 +677,7 @@ func readOutput(r io.Reader, callback ProgressCallback, source string) {
 		}
 
 		// Parse download progress
-		fraction := parseProgress(line)
+		fraction := parse(line)
 
 		// Send the progress update
 		callback(fraction, line)
@@ -182,67 +723,195 @@ func parseProgress(line string) float64 {
 	return -1 // No progress detected, keep current progress
 }
 
+// postprocessTemplate makes yt-dlp print a line whenever a post-processor
+// (merge, audio extraction, thumbnail conversion, move) starts or finishes
+const postprocessTemplate = "postprocess:[postprocess] %(progress.status)s %(progress.postprocessor)s"
+
+var postprocessRegex = regexp.MustCompile(`^\[postprocess\]\s+(started|processing|finished)\b`)
+
+// postprocessStages estimates how many post-processors yt-dlp will run for opts
+func postprocessStages(opts Options) int {
+	stages := 1 // MoveFiles always runs
+	if opts.Format == "mp3" {
+		stages++ // ExtractAudio
+		if opts.embedsArt() {
+			stages += 2 // FFmpegMetadata, EmbedThumbnail
+		}
+	} else if !opts.NoFFmpeg {
+		stages++ // Merger
+		if opts.Format == "mkv" {
+			stages++ // VideoRemuxer
+		}
+	}
+	if opts.WriteThumbnail && !opts.NoFFmpeg {
+		stages++ // ThumbnailsConvertor
+	}
+	if opts.SplitChapters && !opts.NoFFmpeg {
+		stages++ // FFmpegSplitChapters
+	}
+	return stages
+}
+
+// postprocessTracker spreads post-processing over the 0.9-1.0 range of the
+// bar so long merges keep moving instead of sitting at a flat 90%
+type postprocessTracker struct {
+	mu       sync.Mutex
+	expected int
+	finished int
+	active   bool // a post-processor line has been seen
+}
+
+// parse reports progress for line, falling back to parseProgress for
+// anything that isn't a post-processing status line
+func (t *postprocessTracker) parse(line string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	matches := postprocessRegex.FindStringSubmatch(line)
+	if matches == nil {
+		if t.active {
+			// Legacy stage guesses ("merging" = 0.9) would drag the bar back
+			return -1
+		}
+		return parseProgress(line)
+	}
+
+	t.active = true
+	done := float64(t.finished)
+	switch matches[1] {
+	case "finished":
+		t.finished++
+		done = float64(t.finished)
+	default:
+		done += 0.5
+	}
+
+	// Never claim 1.0 here; the success line does that once yt-dlp exits
+	return min(0.9+0.1*done/float64(t.expected+1), 0.99)
+}
+
+var (
+	speedRegex = regexp.MustCompile(`\bat\s+(\d+(?:\.\d+)?\s*[KMGT]?i?B/s)`)
+	etaRegex   = regexp.MustCompile(`\bETA\s+(\d+(?::\d+)+)`)
+	sizeRegex  = regexp.MustCompile(`\bof\s+~?\s*(\d+(?:\.\d+)?)\s*([KMGT]?i?B)\b`)
+	rateRegex  = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([KMGT]?i?B)/s$`)
+)
+
+// sizeUnits maps yt-dlp size suffixes to bytes
+var sizeUnits = map[string]float64{
+	"B":   1,
+	"KiB": 1 << 10,
+	"MiB": 1 << 20,
+	"GiB": 1 << 30,
+	"TiB": 1 << 40,
+	"KB":  1e3,
+	"MB":  1e6,
+	"GB":  1e9,
+	"TB":  1e12,
+}
+
+// ParseTotalBytes extracts the total download size from a yt-dlp progress line, or 0 if absent
+func ParseTotalBytes(line string) int64 {
+	matches := sizeRegex.FindStringSubmatch(line)
+	if len(matches) < 3 {
+		return 0
+	}
+	n, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0
+	}
+	return int64(n * sizeUnits[matches[2]])
+}
+
+// SpeedBytes converts a speed reported by ParseProgressDetails, e.g.
+// "1.20MiB/s", to bytes per second, or 0 if it can't be read
+func SpeedBytes(speed string) float64 {
+	matches := rateRegex.FindStringSubmatch(strings.TrimSpace(speed))
+	if len(matches) < 3 {
+		return 0
+	}
+	n, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0
+	}
+	return n * sizeUnits[matches[2]]
+}
+
+// ParseProgressDetails extracts download speed and ETA from a yt-dlp progress line.
+// Fields yt-dlp reports as "Unknown" come back empty.
+func ParseProgressDetails(line string) (speed string, eta string) {
+	if matches := speedRegex.FindStringSubmatch(line); len(matches) > 1 {
+		speed = matches[1]
+	}
+	if matches := etaRegex.FindStringSubmatch(line); len(matches) > 1 {
+		eta = matches[1]
+	}
+	return speed, eta
+}
+
+// DefaultTitleTimeout bounds how long FetchTitleAsync waits for yt-dlp
+const DefaultTitleTimeout = 10 * time.Second
+
+// EffectiveTitleTimeout returns the configured title timeout, or the default
+func (o Options) EffectiveTitleTimeout() time.Duration {
+	if o.TitleTimeout <= 0 {
+		return DefaultTitleTimeout
+	}
+	return o.TitleTimeout
+}
+
 // FetchTitleAsync fetches video title asynchronously
-func FetchTitleAsync(url string, callback TitleCallback) {
+func FetchTitleAsync(url string, opts Options, callback TitleCallback) {
 	go func() {
 		// Add timeout to prevent hanging
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(context.Background(), opts.EffectiveTitleTimeout())
 		defer cancel()
 
-		cmd := exec.CommandContext(ctx, "yt-dlp", "--get-title", url)
-		var out bytes.Buffer
-		var errOut bytes.Buffer
-		cmd.Stdout = &out
-		cmd.Stderr = &errOut
-
-		err := cmd.Run()
+		// --ignore-no-formats-error lets premieres report their title
+		// instead of failing
+		args := append([]string{"--print", "title", "--print", "live_status",
+			"--ignore-no-formats-error", "--no-playlist"}, requestArgs(opts)...)
+		out, err := runOutput(ctx, ytdlpPath, append(args, url)...)
+		if err != nil && ctx.Err() != nil {
+			err = fmt.Errorf("timed out after %s: %w", opts.EffectiveTitleTimeout(), ctx.Err())
+		}
 
-		title := ""
+		title, liveStatus := "", ""
 		if err == nil {
-			title = strings.TrimSpace(out.String())
+			// not trimmed first: an empty title still takes up its line
+			title, liveStatus, _ = strings.Cut(string(out), "\n")
+			liveStatus = printedField(liveStatus)
 		}
 
-		// If title fetch failed, use URL as fallback
-		if title == "" {
-			if err != nil {
-				title = extractURLName(url)
-				callback(TitleFetchedMsg{
-					URL:   url,
-					Title: title,
-					Error: err,
-				})
-			} else {
-				title = extractURLName(url)
-				callback(TitleFetchedMsg{
-					URL:   url,
-					Title: title,
-					Error: nil,
-				})
-			}
-		} else {
-			callback(TitleFetchedMsg{
-				URL:   url,
-				Title: title,
-				Error: nil,
-			})
-		}
+		callback(TitleFetchedMsg{
+			URL:        url,
+			Title:      titleOrFallback(title, url),
+			LiveStatus: liveStatus,
+			Error:      err,
+		})
 	}()
 }
 
+// titleOrFallback returns the title yt-dlp printed, or a name derived from
+// url when it printed nothing or "NA"
+func titleOrFallback(printed, url string) string {
+	if title := printedField(printed); title != "" {
+		return title
+	}
+	return extractURLName(url)
+}
+
 // FetchTitle synchronous version (kept for backward compatibility, but not recommended)
 func FetchTitle(url string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "yt-dlp", "--get-title", url)
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	err := cmd.Run()
+	out, err := runOutput(ctx, ytdlpPath, "--get-title", url)
 	if err != nil {
 		return extractURLName(url), err
 	}
 
-	title := strings.TrimSpace(out.String())
+	title := strings.TrimSpace(string(out))
 	if title == "" {
 		return extractURLName(url), nil
 	}
@@ -283,25 +952,36 @@ func extractURLName(url string) string {
 	return url
 }
 
-// saveVideoInfo appends metadata to downloads.json
-func saveVideoInfo(url string, path string) {
-	cmd := exec.Command("yt-dlp", "--dump-json", "-f", "bestvideo+bestaudio/best", url)
-
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	if err := cmd.Run(); err != nil {
-		return // skip if metadata fetch fails
+// FetchVideoInfo collects a case's metadata from yt-dlp without downloading
+// it, for the streams opts selects
+func FetchVideoInfo(url string, opts Options) (VideoInfo, error) {
+	args := append([]string{"--dump-json", "-f", formatSelector(opts), "--no-playlist"}, requestArgs(opts)...)
+	out, err := runOutput(context.Background(), ytdlpPath, append(args, url)...)
+	if err != nil {
+		return VideoInfo{}, fmt.Errorf("fetching metadata: %w", err)
 	}
 
 	var raw map[string]interface{}
-	if err := json.Unmarshal(out.Bytes(), &raw); err != nil {
-		return
+	if err := json.Unmarshal(out, &raw); err != nil {
+		return VideoInfo{}, fmt.Errorf("parsing metadata: %w", err)
 	}
 
 	info := VideoInfo{
-		URL:          url,
-		Title:        raw["title"].(string),
-		DownloadedAt: time.Now(),
+		URL:         CanonicalizeURL(url),
+		Title:       extractURLName(url),
+		Format:      opts.Format,
+		EmbeddedArt: opts.embedsArt(),
+	}
+	if opts.Format == "mp3" {
+		info.AudioFormat = audioFormat(opts)
+	}
+	if opts.isClip() {
+		info.ClipStart, info.ClipEnd = opts.ClipStart, opts.ClipEnd
+	}
+
+	// Some extractors omit the title or report it as null
+	if t, ok := raw["title"].(string); ok && t != "" {
+		info.Title = t
 	}
 
 	if d, ok := raw["duration"].(float64); ok {
@@ -312,6 +992,9 @@ func saveVideoInfo(url string, path string) {
 	}
 	if s, ok := raw["filesize"].(float64); ok {
 		info.Filesize = int64(s)
+	} else if s, ok := raw["filesize_approx"].(float64); ok {
+		// merged formats usually only carry yt-dlp's estimate
+		info.Filesize = int64(s)
 	}
 
 	if w, ok := raw["width"].(float64); ok {
@@ -333,16 +1016,111 @@ func saveVideoInfo(url string, path string) {
 		info.TBR = t
 	}
 
-	var infos []VideoInfo
-	if data, err := os.ReadFile(path); err == nil {
-		json.Unmarshal(data, &infos)
+	if e, ok := raw["extractor_key"].(string); ok {
+		info.Extractor = e
+	} else if e, ok := raw["extractor"].(string); ok {
+		info.Extractor = e
+	}
+	if c, ok := raw["channel"].(string); ok {
+		info.Channel = c
+	} else if u, ok := raw["uploader"].(string); ok {
+		info.Channel = u
+	}
+	if w, ok := raw["webpage_url"].(string); ok {
+		info.WebpageURL = w
 	}
+	if l, ok := raw["live_status"].(string); ok {
+		info.LiveStatus = l
+	}
+	if opts.Format == "mp3" {
+		audioOnly(&info, opts)
+	}
+
+	if opts.DownloadSubs {
+		if subs, ok := raw["subtitles"].(map[string]interface{}); ok {
+			for _, lang := range subLangs(opts) {
+				if _, ok := subs[lang]; ok {
+					info.HasSubtitles = true
+					break
+				}
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// audioOnly clears the video fields of an audio extraction, which keeps no
+// video stream, and records the bitrate a lossy codec was encoded at
+func audioOnly(info *VideoInfo, opts Options) {
+	info.Resolution = "audio only"
+	info.Width, info.Height, info.FPS, info.VBR = 0, 0, 0, 0
+	if !LosslessAudio(audioFormat(opts)) {
+		if kbps, err := strconv.ParseFloat(strings.TrimSuffix(audioQuality(opts), "K"), 64); err == nil {
+			info.ABR = kbps
+		}
+	}
+	info.TBR = info.ABR
+}
+
+// saveVideoInfo appends metadata to downloads.json
+func saveVideoInfo(url string, opts Options, filePath string, chapters []string, path string) {
+	info, err := FetchVideoInfo(url, opts)
+	if err != nil {
+		return // skip if metadata fetch fails
+	}
+	info.DownloadedAt = time.Now()
 
-	infos = append(infos, info)
+	if filePath != "" {
+		if abs, err := filepath.Abs(filePath); err == nil {
+			filePath = abs
+		}
+		info.FilePath = filePath
+		if stat, err := os.Stat(filePath); err == nil {
+			// yt-dlp only knows the source streams, not what merging or extraction wrote
+			info.Filesize = stat.Size()
+		}
+		info.UniqueName = opts.UniqueName
+		info.Container = strings.ToLower(strings.TrimPrefix(filepath.Ext(filePath), "."))
+	}
+	for _, chapter := range chapters {
+		if abs, err := filepath.Abs(chapter); err == nil {
+			chapter = abs
+		}
+		info.ChapterFiles = append(info.ChapterFiles, chapter)
+	}
+
+	base := info.Title
+	if info.FilePath != "" {
+		base = strings.TrimSuffix(info.FilePath, filepath.Ext(info.FilePath))
+	}
+	if opts.WriteThumbnail {
+		if thumb := base + ".jpg"; fileExists(thumb) {
+			info.ThumbnailPath = thumb
+		}
+	}
+	if opts.WriteMetadata {
+		for _, ext := range []string{".info.json", ".description"} {
+			if sidecar := base + ext; fileExists(sidecar) {
+				info.Sidecars = append(info.Sidecars, sidecar)
+			}
+		}
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	// a corrupted file has already been backed up, so build on what survived
+	infos, err := LoadHistory(path)
+	var corrupt *CorruptHistoryError
+	if err != nil && !errors.As(err, &corrupt) {
+		return
+	}
 
-	if data, err := json.MarshalIndent(infos, "", "  "); err == nil {
-		os.WriteFile(path, data, 0644)
+	if opts.Overwrite {
+		infos, info = replaceRecords(infos, info)
 	}
+	writeHistory(path, pruneHistory(append(infos, info), opts.MaxHistory))
 }
 
 // Helper functions (kept for compatibility)
@@ -0,0 +1,82 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveVideoInfoWithoutTitle(t *testing.T) {
+	for name, data := range map[string]string{
+		"missing": `{"duration":12}`,
+		"null":    `{"title":null,"duration":12}`,
+		"empty":   `{"title":"","duration":12}`,
+		"number":  `{"title":42,"duration":12}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			useRunner(t, func(args []string) fakeRun { return fakeRun{stdout: data} })
+			path := filepath.Join(t.TempDir(), "downloads.json")
+
+			saveVideoInfo(watchURL, Options{Format: "mp4"}, "", nil, path)
+
+			infos, err := readHistory(path)
+			if err != nil || len(infos) != 1 {
+				t.Fatalf("history = %v, %v, want one record", infos, err)
+			}
+			if got := infos[0].Title; got != "YouTube Video: dQw4w9WgXcQ" {
+				t.Errorf("Title = %q, want the URL-derived name", got)
+			}
+			if infos[0].Duration != 12 {
+				t.Errorf("Duration = %v, want the rest of the metadata kept", infos[0].Duration)
+			}
+		})
+	}
+}
+
+func TestProgressContractWithFullBuffer(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	var out strings.Builder
+	for i := range 100 {
+		fmt.Fprintf(&out, "[download]  %d.0%% of 10.00MiB at 1.00MiB/s ETA 00:09\n", i)
+	}
+	useRunner(t, func(args []string) fakeRun {
+		if isDumpJSON(args) {
+			return fakeRun{stdout: videoJSON}
+		}
+		return fakeRun{stdout: out.String()}
+	})
+
+	pool := NewPool(1)
+	if !pool.TryAcquire() {
+		t.Fatal("couldn't claim the only slot")
+	}
+	ch := make(chan ProgressFractionMsg, 4)
+	DownloadStreamWithProgress(context.Background(), watchURL, Options{Format: "mp4", Pool: pool}, ch)
+
+	// nobody reads ch, the download must still finish and free its slot
+	deadline := time.Now().Add(5 * time.Second)
+	for !pool.TryAcquire() {
+		if time.Now().After(deadline) {
+			t.Fatal("download blocked on a full progress channel")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	msgs := collect(t, ch)
+	done := 0
+	for _, msg := range msgs {
+		if msg.Done {
+			done++
+		}
+	}
+	if done != 1 {
+		t.Fatalf("%d Done messages, want exactly 1", done)
+	}
+	if last := msgs[len(msgs)-1]; !last.Done || last.Err != nil {
+		t.Errorf("last message before the close = %+v, want a successful Done", last)
+	}
+}
@@ -0,0 +1,89 @@
+package downloader
+
+import "strings"
+
+// DownloadError is a yt-dlp failure with the reason it gave
+type DownloadError struct {
+	Message   string // yt-dlp's last ERROR line, without the prefix
+	Reason    string // short label such as "GEO-BLOCKED" or "NETWORK", empty when unrecognised
+	Permanent bool   // retrying can't help
+	Err       error  // the process exit error
+}
+
+func (e *DownloadError) Error() string {
+	return e.Message + ": " + e.Err.Error()
+}
+
+func (e *DownloadError) Unwrap() error {
+	return e.Err
+}
+
+// permanentErrors map phrases in yt-dlp errors to a reason. More specific
+// phrases come first since yt-dlp often prefixes them with "Video unavailable".
+var permanentErrors = []struct {
+	phrase string
+	reason string
+}{
+	{"private video", "PRIVATE"},
+	{"video is private", "PRIVATE"},
+	{"not made this video available in your country", "GEO-BLOCKED"},
+	{"not available in your country", "GEO-BLOCKED"},
+	{"blocked it in your country", "GEO-BLOCKED"},
+	{"geo restriction", "GEO-BLOCKED"},
+	{"copyright", "COPYRIGHT CLAIM"},
+	{"has been removed", "REMOVED"},
+	{"account associated with this video has been terminated", "REMOVED"},
+	{"members-only", "MEMBERS ONLY"},
+	{"unsupported url", "UNSUPPORTED URL"},
+	{"requested format is not available", "FORMAT UNAVAILABLE"},
+	{"premieres in", "SCHEDULED"},
+	{"live event will begin", "SCHEDULED"},
+	{"video unavailable", "UNAVAILABLE"},
+	{"this video is unavailable", "UNAVAILABLE"},
+}
+
+// transientErrors label failures that a later retry may get past
+var transientErrors = []struct {
+	phrase string
+	reason string
+}{
+	{"http error 429", "RATE LIMITED"},
+	{"too many requests", "RATE LIMITED"},
+	{"name or service not known", "NETWORK"},
+	{"temporary failure in name resolution", "NETWORK"},
+	{"getaddrinfo failed", "NETWORK"},
+	{"connection refused", "NETWORK"},
+	{"connection reset", "NETWORK"},
+	{"timed out", "NETWORK"},
+	{"unable to download webpage", "NETWORK"},
+}
+
+// newDownloadError classifies the ERROR line that ended a failed attempt
+func newDownloadError(message string, err error) *DownloadError {
+	e := &DownloadError{Message: message, Err: err}
+	lower := strings.ToLower(message)
+	for _, p := range permanentErrors {
+		if strings.Contains(lower, p.phrase) {
+			e.Reason, e.Permanent = p.reason, true
+			return e
+		}
+	}
+	for _, p := range transientErrors {
+		if strings.Contains(lower, p.phrase) {
+			e.Reason = p.reason
+			return e
+		}
+	}
+	return e
+}
+
+// lastErrorLine returns yt-dlp's last ERROR line in output, without the prefix
+func lastErrorLine(output string) string {
+	message := ""
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "ERROR:") {
+			message = strings.TrimSpace(strings.TrimPrefix(line, "ERROR:"))
+		}
+	}
+	return message
+}
@@ -0,0 +1,40 @@
+package downloader
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvHeader names the columns written by ExportCSV
+var csvHeader = []string{"title", "url", "duration_seconds", "resolution", "size_bytes", "downloaded_at"}
+
+// ExportCSV writes one row per record in infos, quoting fields as needed
+func ExportCSV(infos []VideoInfo, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		downloaded := ""
+		if !info.DownloadedAt.IsZero() {
+			downloaded = info.DownloadedAt.Format(time.RFC3339)
+		}
+		row := []string{
+			info.Title,
+			info.URL,
+			strconv.FormatFloat(info.Duration, 'f', -1, 64),
+			info.Resolution,
+			strconv.FormatInt(info.Filesize, 10),
+			downloaded,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,76 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Format is one downloadable stream variant reported by yt-dlp
+type Format struct {
+	ID         string  `json:"format_id"`
+	Ext        string  `json:"ext"`
+	Resolution string  `json:"resolution"`
+	FPS        float64 `json:"fps"`
+	VCodec     string  `json:"vcodec"`
+	ACodec     string  `json:"acodec"`
+	Filesize   int64   `json:"-"` // exact size, or yt-dlp's estimate when that's all it has
+	TBR        float64 `json:"tbr"`
+	Note       string  `json:"format_note"`
+}
+
+// HasVideo reports whether the format carries a video stream
+func (f Format) HasVideo() bool {
+	return f.VCodec != "" && f.VCodec != "none"
+}
+
+// HasAudio reports whether the format carries an audio stream
+func (f Format) HasAudio() bool {
+	return f.ACodec != "" && f.ACodec != "none"
+}
+
+// Selector returns the -f value that downloads f. Video-only formats get
+// the best audio merged in when ffmpeg is available.
+func (f Format) Selector(noFFmpeg bool) string {
+	if f.HasVideo() && !f.HasAudio() && !noFFmpeg {
+		return f.ID + "+bestaudio/" + f.ID
+	}
+	return f.ID
+}
+
+// ListFormats asks yt-dlp for every format available at url
+func ListFormats(url string, opts Options) ([]Format, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	args := append([]string{"--dump-json", "--no-playlist"}, requestArgs(opts)...)
+	out, err := runOutput(ctx, ytdlpPath, append(args, url)...)
+	if err != nil {
+		return nil, fmt.Errorf("listing formats: %w", err)
+	}
+
+	// filesize and filesize_approx may be null, so decode them separately
+	var raw struct {
+		Formats []struct {
+			Format
+			Filesize       *float64 `json:"filesize"`
+			FilesizeApprox *float64 `json:"filesize_approx"`
+		} `json:"formats"`
+	}
+	if err := json.Unmarshal(out, &raw); err != nil {
+		return nil, fmt.Errorf("parsing formats: %w", err)
+	}
+
+	formats := make([]Format, 0, len(raw.Formats))
+	for _, r := range raw.Formats {
+		f := r.Format
+		if r.Filesize != nil {
+			f.Filesize = int64(*r.Filesize)
+		} else if r.FilesizeApprox != nil {
+			f.Filesize = int64(*r.FilesizeApprox)
+		}
+		formats = append(formats, f)
+	}
+	return formats, nil
+}
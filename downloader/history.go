@@ -0,0 +1,466 @@
+package downloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stats aggregates an archive's history
+type Stats struct {
+	Count         int
+	TotalSize     int64   // bytes
+	TotalDuration float64 // seconds
+	Oldest        time.Time
+	Newest        time.Time
+}
+
+// HistoryStats sums up infos
+func HistoryStats(infos []VideoInfo) Stats {
+	var s Stats
+	for _, info := range infos {
+		s.Count++
+		s.TotalSize += info.Filesize
+		s.TotalDuration += info.Duration
+		if s.Oldest.IsZero() || info.DownloadedAt.Before(s.Oldest) {
+			s.Oldest = info.DownloadedAt
+		}
+		if info.DownloadedAt.After(s.Newest) {
+			s.Newest = info.DownloadedAt
+		}
+	}
+	return s
+}
+
+// SourceBreakdown groups infos by source site: the extractor yt-dlp used,
+// or the URL's host for records that don't name one
+func SourceBreakdown(infos []VideoInfo) map[string]Stats {
+	groups := map[string][]VideoInfo{}
+	for _, info := range infos {
+		source := strings.ToLower(info.Extractor)
+		if source == "" {
+			if u, err := url.Parse(info.URL); err == nil && u.Hostname() != "" {
+				source = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+			} else {
+				source = "unknown"
+			}
+		}
+		groups[source] = append(groups[source], info)
+	}
+
+	breakdown := make(map[string]Stats, len(groups))
+	for source, group := range groups {
+		breakdown[source] = HistoryStats(group)
+	}
+	return breakdown
+}
+
+// readHistory loads every record from a history file
+func readHistory(path string) ([]VideoInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var infos []VideoInfo
+	if err := json.Unmarshal(data, &infos); err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// CorruptHistoryError is returned by LoadHistory when the history file
+// isn't valid JSON. The original was copied to Backup before salvaging.
+type CorruptHistoryError struct {
+	Backup   string // "" if the backup couldn't be written
+	Salvaged int    // records recovered from before the damage
+	Err      error
+}
+
+func (e *CorruptHistoryError) Error() string {
+	return fmt.Sprintf("history file is corrupted (%d records salvaged): %v", e.Salvaged, e.Err)
+}
+
+func (e *CorruptHistoryError) Unwrap() error {
+	return e.Err
+}
+
+// LoadHistory reads the history file at path. A missing file is an empty
+// history. When the file is corrupted it is backed up to path+".bak" and the
+// records that still parse are returned with a *CorruptHistoryError, so a
+// later save can't silently throw the rest away.
+func LoadHistory(path string) ([]VideoInfo, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []VideoInfo
+	err = json.Unmarshal(data, &infos)
+	if err == nil {
+		return infos, nil
+	}
+
+	corrupt := &CorruptHistoryError{Err: err}
+	if os.WriteFile(path+".bak", data, 0644) == nil {
+		corrupt.Backup = path + ".bak"
+	}
+	infos = salvageHistory(data)
+	corrupt.Salvaged = len(infos)
+	return infos, corrupt
+}
+
+// salvageHistory decodes records from a damaged history array one at a
+// time, keeping everything before the first one that fails
+func salvageHistory(data []byte) []VideoInfo {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil
+	}
+
+	var infos []VideoInfo
+	for dec.More() {
+		var info VideoInfo
+		if err := dec.Decode(&info); err != nil {
+			break
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// historyMu serializes read-modify-write cycles on history files, since
+// parallel downloads finish, and save their records, at the same time
+var historyMu sync.Mutex
+
+// writeHistory replaces the contents of a history file. The new contents
+// are written to a temporary file first and renamed over the old one, so
+// readers never see a half-written file. Callers hold historyMu.
+func writeHistory(path string, infos []VideoInfo) error {
+	data, err := json.MarshalIndent(infos, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// DeleteVideoInfo removes every record for url from the history file at
+// path, returning the removed records so the deletion can be undone
+func DeleteVideoInfo(path string, url string) ([]VideoInfo, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	infos, err := readHistory(path)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := []VideoInfo{}
+	var removed []VideoInfo
+	for _, info := range infos {
+		if info.URL != url {
+			kept = append(kept, info)
+		} else {
+			removed = append(removed, info)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, fmt.Errorf("no archived case for %s", url)
+	}
+
+	return removed, writeHistory(path, kept)
+}
+
+// RestoreVideoInfo appends records removed by DeleteVideoInfo back to the
+// history file at path
+func RestoreVideoInfo(path string, restored []VideoInfo) error {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	infos, err := readHistory(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return writeHistory(path, append(infos, restored...))
+}
+
+// NormalizeTag trims and lowercases a tag so "Music " and "music" match
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// ToggleTag adds tag to every record for url in the history file at path,
+// or removes it when they already carry it. added reports which happened.
+func ToggleTag(path string, url string, tag string) (added bool, err error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	tag = NormalizeTag(tag)
+	if tag == "" {
+		return false, fmt.Errorf("empty tag")
+	}
+	infos, err := readHistory(path)
+	if err != nil {
+		return false, err
+	}
+
+	found := false
+	for i := range infos {
+		if infos[i].URL != url {
+			continue
+		}
+		found = true
+		if j := slices.Index(infos[i].Tags, tag); j >= 0 {
+			infos[i].Tags = slices.Delete(infos[i].Tags, j, j+1)
+		} else {
+			infos[i].Tags = append(infos[i].Tags, tag)
+			added = true
+		}
+	}
+	if !found {
+		return false, fmt.Errorf("no archived case for %s", url)
+	}
+
+	return added, writeHistory(path, infos)
+}
+
+// ToggleFavorite pins every record for url in the history file at path,
+// or unpins them when they are already pinned
+func ToggleFavorite(path string, url string) (pinned bool, err error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	infos, err := readHistory(path)
+	if err != nil {
+		return false, err
+	}
+
+	i := slices.IndexFunc(infos, func(info VideoInfo) bool { return info.URL == url })
+	if i < 0 {
+		return false, fmt.Errorf("no archived case for %s", url)
+	}
+	pinned = !infos[i].IsFavorite
+	for i := range infos {
+		if infos[i].URL == url {
+			infos[i].IsFavorite = pinned
+		}
+	}
+
+	return pinned, writeHistory(path, infos)
+}
+
+// ToggleWatch opts every record for url in the history file at path into
+// CheckForUpdate, or out again when they are already watched
+func ToggleWatch(path string, url string) (watching bool, err error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	infos, err := readHistory(path)
+	if err != nil {
+		return false, err
+	}
+
+	i := slices.IndexFunc(infos, func(info VideoInfo) bool { return info.URL == url })
+	if i < 0 {
+		return false, fmt.Errorf("no archived case for %s", url)
+	}
+	watching = !infos[i].WatchUpdates
+	for i := range infos {
+		if infos[i].URL == url {
+			infos[i].WatchUpdates = watching
+		}
+	}
+
+	return watching, writeHistory(path, infos)
+}
+
+// replaceRecords drops the records that info supersedes (same URL and clip),
+// carrying their tags, pin and watch over to info
+func replaceRecords(infos []VideoInfo, info VideoInfo) ([]VideoInfo, VideoInfo) {
+	kept := infos[:0]
+	for _, old := range infos {
+		if old.URL == info.URL && old.ClipStart == info.ClipStart && old.ClipEnd == info.ClipEnd {
+			info.IsFavorite = info.IsFavorite || old.IsFavorite
+			info.WatchUpdates = info.WatchUpdates || old.WatchUpdates
+			for _, tag := range old.Tags {
+				if !slices.Contains(info.Tags, tag) {
+					info.Tags = append(info.Tags, tag)
+				}
+			}
+			continue
+		}
+		kept = append(kept, old)
+	}
+	return kept, info
+}
+
+// pruneHistory drops the oldest records (by DownloadedAt) so at most max
+// remain, keeping the survivors in their original order. max <= 0 keeps everything.
+func pruneHistory(infos []VideoInfo, max int) []VideoInfo {
+	if max <= 0 || len(infos) <= max {
+		return infos
+	}
+
+	order := make([]int, len(infos))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return infos[order[a]].DownloadedAt.Before(infos[order[b]].DownloadedAt)
+	})
+
+	dropped := make(map[int]bool, len(infos)-max)
+	for _, i := range order[:len(infos)-max] {
+		dropped[i] = true
+	}
+
+	kept := make([]VideoInfo, 0, max)
+	for i, info := range infos {
+		if !dropped[i] {
+			kept = append(kept, info)
+		}
+	}
+	return kept
+}
+
+// PruneHistory trims the history file at path to its max newest records and
+// reports how many were removed. Media files are left untouched.
+func PruneHistory(path string, max int) (int, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	infos, err := readHistory(path)
+	if err != nil {
+		return 0, err
+	}
+
+	kept := pruneHistory(infos, max)
+	if len(kept) == len(infos) {
+		return 0, nil
+	}
+	return len(infos) - len(kept), writeHistory(path, kept)
+}
+
+// fileExists reports whether path names an existing file
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// claimedBy returns the record of a video other than url whose archive is
+// filePath, meaning a download that ended there collided with it
+func claimedBy(path, url, filePath string) (VideoInfo, bool) {
+	if filePath == "" {
+		return VideoInfo{}, false
+	}
+	if abs, err := filepath.Abs(filePath); err == nil {
+		filePath = abs
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	infos, err := readHistory(path)
+	if err != nil {
+		return VideoInfo{}, false
+	}
+	for _, info := range infos {
+		if info.FilePath == filePath && info.URL != url {
+			return info, true
+		}
+	}
+	return VideoInfo{}, false
+}
+
+// MediaPath locates the file behind an archived case, returning "" when nothing is found
+func MediaPath(info VideoInfo) string {
+	if info.FilePath != "" {
+		if fileExists(info.FilePath) {
+			return info.FilePath
+		}
+		return ""
+	}
+
+	// Older records don't carry a path; fall back to the default template
+	for _, ext := range []string{"mp4", "mkv", "mp3", "m4a", "opus", "flac", "wav"} {
+		if candidate := info.Title + "." + ext; fileExists(candidate) {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// DeleteMediaFile removes the file behind an archived case, along with
+// any chapter files split from it and the subtitle, thumbnail and metadata
+// sidecars written next to it. Files that are already gone are not an error.
+func DeleteMediaFile(info VideoInfo) error {
+	path := MediaPath(info)
+	files := append(slices.Clone(info.ChapterFiles), sidecarFiles(info, path)...)
+	if path != "" {
+		files = append(files, path)
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// subtitleExts are the formats yt-dlp writes subtitles in, as
+// <media name>.<lang>.<ext>
+var subtitleExts = []string{"vtt", "srt", "ass", "ssa", "ttml", "srv1", "srv2", "srv3", "json3", "lrc"}
+
+// sidecarFiles lists the files written next to the media at path: the
+// recorded thumbnail and metadata files, and any subtitles
+func sidecarFiles(info VideoInfo, path string) []string {
+	var files []string
+	if info.ThumbnailPath != "" {
+		files = append(files, info.ThumbnailPath)
+	}
+	files = append(files, info.Sidecars...)
+	if path == "" {
+		return files
+	}
+
+	// read the directory rather than globbing, titles may contain pattern characters
+	dir := filepath.Dir(path)
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		rest, ok := strings.CutPrefix(entry.Name(), name+".")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if lang, ext, ok := strings.Cut(rest, "."); ok && lang != "" && slices.Contains(subtitleExts, ext) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files
+}
@@ -0,0 +1,185 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLoadHistoryMissingFile(t *testing.T) {
+	infos, err := LoadHistory(filepath.Join(t.TempDir(), "downloads.json"))
+	if err != nil || len(infos) != 0 {
+		t.Errorf("LoadHistory = %v, %v, want an empty history", infos, err)
+	}
+}
+
+func TestLoadHistoryCorrupted(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		salvaged []string // titles expected back
+	}{
+		{
+			name:     "truncated array",
+			data:     `[{"url":"https://a.example/1","title":"One"},{"url":"https://a.example/2","title":"Two"},{"url":"https://a.ex`,
+			salvaged: []string{"One", "Two"},
+		},
+		{
+			name:     "damaged record",
+			data:     `[{"url":"https://a.example/1","title":"One"},{"url":, "title":"Two"},{"url":"https://a.example/3","title":"Three"}]`,
+			salvaged: []string{"One"},
+		},
+		{
+			name: "not an array",
+			data: `{"url":"https://a.example/1","title":"One"}`,
+		},
+		{
+			name: "garbage",
+			data: "\x00\x01 definitely not json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "downloads.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			infos, err := LoadHistory(path)
+			var corrupt *CorruptHistoryError
+			if !errors.As(err, &corrupt) {
+				t.Fatalf("LoadHistory error = %v, want a *CorruptHistoryError", err)
+			}
+			if corrupt.Salvaged != len(tt.salvaged) || len(infos) != len(tt.salvaged) {
+				t.Fatalf("salvaged %d (%d returned), want %d", corrupt.Salvaged, len(infos), len(tt.salvaged))
+			}
+			for i, title := range tt.salvaged {
+				if infos[i].Title != title {
+					t.Errorf("record %d = %q, want %q", i, infos[i].Title, title)
+				}
+			}
+
+			if corrupt.Backup != path+".bak" {
+				t.Errorf("Backup = %q, want %q", corrupt.Backup, path+".bak")
+			}
+			backup, err := os.ReadFile(path + ".bak")
+			if err != nil {
+				t.Fatalf("backup not written: %v", err)
+			}
+			if string(backup) != tt.data {
+				t.Error("backup doesn't hold the original contents")
+			}
+		})
+	}
+}
+
+// barrierRunner holds every metadata lookup until n of them are waiting,
+// so the history writes that follow them all overlap
+type barrierRunner struct {
+	n       int
+	mu      sync.Mutex
+	arrived int
+	all     chan struct{}
+}
+
+func (r *barrierRunner) Run(ctx context.Context, name string, args ...string) (io.Reader, io.Reader, func() error, error) {
+	r.mu.Lock()
+	if r.arrived++; r.arrived == r.n {
+		close(r.all)
+	}
+	r.mu.Unlock()
+	<-r.all
+
+	out := fmt.Sprintf(`{"title":%q}`, args[len(args)-1])
+	return strings.NewReader(out), strings.NewReader(""), func() error { return nil }, nil
+}
+
+func TestParallelSavesKeepEveryRecord(t *testing.T) {
+	const n = 20
+	old := Runner
+	Runner = &barrierRunner{n: n, all: make(chan struct{})}
+	t.Cleanup(func() { Runner = old })
+	path := filepath.Join(t.TempDir(), "downloads.json")
+
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			saveVideoInfo(fmt.Sprintf("https://example.com/v%d", i), Options{Format: "mp4"}, "", nil, path)
+		}()
+	}
+	wg.Wait()
+
+	infos, err := LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := make(map[string]bool)
+	for _, info := range infos {
+		saved[info.URL] = true
+	}
+	for i := range n {
+		if url := fmt.Sprintf("https://example.com/v%d", i); !saved[url] {
+			t.Errorf("record for %s was lost", url)
+		}
+	}
+	if len(infos) != n {
+		t.Errorf("%d records saved, want %d", len(infos), n)
+	}
+}
+
+func TestDeleteMediaFileRemovesSidecars(t *testing.T) {
+	dir := t.TempDir()
+	name := "A [weird] title?"
+	file := func(n string) string { return filepath.Join(dir, n) }
+
+	removed := []string{
+		file(name + ".mp4"),
+		file(name + ".en.vtt"),
+		file(name + ".de-DE.srt"),
+		file(name + ".jpg"),
+		file(name + ".info.json"),
+		file(name + ".description"),
+		file(name + " - 001 Intro.mp4"),
+	}
+	kept := []string{
+		file(name + " 2.mp4"),
+		file(name + " 2.en.vtt"),
+		file("Other.en.vtt"),
+		file(name + ".notes.txt"),
+	}
+	for _, f := range append(removed, kept...) {
+		if err := os.WriteFile(f, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	info := VideoInfo{
+		FilePath:      file(name + ".mp4"),
+		ThumbnailPath: file(name + ".jpg"),
+		Sidecars:      []string{file(name + ".info.json"), file(name + ".description")},
+		ChapterFiles:  []string{file(name + " - 001 Intro.mp4"), file(name + " - 002 gone.mp4")},
+	}
+	if err := DeleteMediaFile(info); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range removed {
+		if fileExists(f) {
+			t.Errorf("%s was left behind", filepath.Base(f))
+		}
+	}
+	for _, f := range kept {
+		if !fileExists(f) {
+			t.Errorf("%s belonged to something else but was deleted", filepath.Base(f))
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// live_status values reported by yt-dlp for streams that can't be
+// downloaded like a regular video
+const (
+	LiveStatusLive     = "is_live"     // broadcasting now, never finishes on its own
+	LiveStatusUpcoming = "is_upcoming" // premiere or scheduled stream that hasn't started
+)
+
+// ErrScheduled reports a premiere or scheduled stream that hasn't
+// started yet
+var ErrScheduled = errors.New("scheduled — not yet available")
+
+// LiveStatus asks yt-dlp for url's live_status, returning "" for sites
+// that don't report one
+func LiveStatus(url string, opts Options) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.EffectiveTitleTimeout())
+	defer cancel()
+
+	args := append([]string{"--print", "live_status", "--ignore-no-formats-error", "--no-playlist"}, requestArgs(opts)...)
+	out, err := runOutput(ctx, ytdlpPath, append(args, url)...)
+	if err != nil {
+		return "", err
+	}
+	return printedField(string(out)), nil
+}
+
+// IsLive reports whether url is broadcasting live right now, returning
+// ErrScheduled for premieres and scheduled streams that haven't started
+func IsLive(url string) (bool, error) {
+	status, err := LiveStatus(url, Options{})
+	if err != nil {
+		return false, err
+	}
+	if status == LiveStatusUpcoming {
+		return false, ErrScheduled
+	}
+	return status == LiveStatusLive, nil
+}
+
+// printedField cleans up a --print value; yt-dlp prints "NA" for fields
+// the extractor didn't set
+func printedField(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "NA" {
+		return ""
+	}
+	return s
+}
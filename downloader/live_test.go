@@ -0,0 +1,50 @@
+package downloader
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLiveStatus(t *testing.T) {
+	for printed, want := range map[string]string{
+		"is_live\n":     LiveStatusLive,
+		"is_upcoming\n": LiveStatusUpcoming,
+		"not_live\n":    "not_live",
+		"NA\n":          "",
+	} {
+		useRunner(t, func(args []string) fakeRun { return fakeRun{stdout: printed} })
+		if got, err := LiveStatus(watchURL, Options{}); err != nil || got != want {
+			t.Errorf("LiveStatus with %q printed = %q, %v, want %q", printed, got, err, want)
+		}
+	}
+}
+
+func TestIsLive(t *testing.T) {
+	tests := []struct {
+		run  fakeRun
+		live bool
+		err  error
+	}{
+		{fakeRun{stdout: "is_live\n"}, true, nil},
+		{fakeRun{stdout: "was_live\n"}, false, nil},
+		{fakeRun{stdout: "NA\n"}, false, nil},
+		{fakeRun{stdout: "is_upcoming\n"}, false, ErrScheduled},
+	}
+
+	for _, tt := range tests {
+		f := useRunner(t, func(args []string) fakeRun { return tt.run })
+		live, err := IsLive(watchURL)
+		if live != tt.live || !errors.Is(err, tt.err) {
+			t.Errorf("IsLive with %q printed = %v, %v, want %v, %v", tt.run.stdout, live, err, tt.live, tt.err)
+		}
+		if args := f.calls[0]; !slices.Contains(args, "live_status") || args[len(args)-1] != watchURL {
+			t.Errorf("args = %q", args)
+		}
+	}
+
+	useRunner(t, func(args []string) fakeRun { return fakeRun{stderr: "ERROR: Private video\n", err: exitFailure()} })
+	if live, err := IsLive(watchURL); live || err == nil || errors.Is(err, ErrScheduled) {
+		t.Errorf("IsLive on a failed lookup = %v, %v, want the lookup error", live, err)
+	}
+}
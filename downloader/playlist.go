@@ -0,0 +1,148 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxPlaylistEntries caps how many videos ExpandPlaylist will return, set
+// from the config at startup
+var MaxPlaylistEntries = 50
+
+// IsPlaylist reports whether the URL points at a playlist rather than a single video
+func IsPlaylist(rawURL string) bool {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return false
+	}
+
+	q := u.Query()
+	return q.Get("list") != "" && q.Get("v") == ""
+}
+
+// InPlaylist reports whether the URL points at a single video opened from a
+// playlist, e.g. watch?v=ID&list=PL. Such links are downloaded as the video
+// alone unless PlaylistURL is used to ask for the whole list.
+func InPlaylist(rawURL string) bool {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return false
+	}
+
+	q := u.Query()
+	return q.Get("list") != "" && q.Get("v") != ""
+}
+
+// PlaylistURL returns the playlist a video link was opened from, or the URL
+// unchanged when it carries no list
+func PlaylistURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Query().Get("list") == "" {
+		return rawURL
+	}
+	if youtubeHosts[strings.ToLower(u.Hostname())] {
+		return "https://www.youtube.com/playlist?list=" + url.QueryEscape(u.Query().Get("list"))
+	}
+
+	q := u.Query()
+	q.Del("v")
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// ValidatePlaylistItems checks a --playlist-items selection: comma
+// separated 1-based indexes and ranges such as "1-10,15,20-" where a
+// missing end runs to the last video
+func ValidatePlaylistItems(spec string) error {
+	if strings.TrimSpace(spec) == "" {
+		return fmt.Errorf("no items selected")
+	}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(from)
+		if err != nil || start < 1 {
+			return fmt.Errorf("%q is not an item number or N-M range", part)
+		}
+		if !isRange || to == "" {
+			continue
+		}
+		end, err := strconv.Atoi(to)
+		if err != nil || end < start {
+			return fmt.Errorf("range %q must end at or after %d", part, start)
+		}
+	}
+	return nil
+}
+
+// playlistEntry is the part of a --flat-playlist --dump-json line that
+// locates the video
+type playlistEntry struct {
+	ID           string `json:"id"`
+	URL          string `json:"url"`
+	WebpageURL   string `json:"webpage_url"`
+	IEKey        string `json:"ie_key"`
+	ExtractorKey string `json:"extractor_key"`
+}
+
+// videoURL returns where the entry's video can be downloaded from, or ""
+// when the entry doesn't say. Only YouTube IDs can be turned into a link.
+func (e playlistEntry) videoURL() string {
+	for _, u := range []string{e.WebpageURL, e.URL} {
+		if IsSupportedURL(u) {
+			return u
+		}
+	}
+	if strings.EqualFold(e.IEKey, "youtube") || strings.EqualFold(e.ExtractorKey, "youtube") {
+		if validVideoID(e.ID) {
+			return "https://www.youtube.com/watch?v=" + e.ID
+		}
+	}
+	return ""
+}
+
+// ExpandPlaylist resolves a playlist URL into individual video URLs,
+// limited to opts.PlaylistItems when that is valid
+func ExpandPlaylist(playlistURL string, opts Options) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	args := []string{"--flat-playlist", "--dump-json"}
+	if ValidatePlaylistItems(opts.PlaylistItems) == nil {
+		args = append(args, "--playlist-items", strings.ReplaceAll(opts.PlaylistItems, " ", ""))
+	} else {
+		args = append(args, "--playlist-end", strconv.Itoa(MaxPlaylistEntries))
+	}
+	args = append(args, requestArgs(opts)...)
+	out, err := runOutput(ctx, ytdlpPath, append(args, playlistURL)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var urls []string
+	for _, line := range strings.Split(string(out), "\n") {
+		var entry playlistEntry
+		if json.Unmarshal([]byte(line), &entry) != nil {
+			continue
+		}
+		u := entry.videoURL()
+		if u == "" {
+			continue
+		}
+		urls = append(urls, u)
+		if len(urls) >= MaxPlaylistEntries {
+			break
+		}
+	}
+
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("playlist is empty")
+	}
+	return urls, nil
+}
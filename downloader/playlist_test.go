@@ -0,0 +1,65 @@
+package downloader
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExpandPlaylist(t *testing.T) {
+	entries := strings.Join([]string{
+		`{"id":"dQw4w9WgXcQ","url":"https://www.youtube.com/watch?v=dQw4w9WgXcQ","ie_key":"Youtube"}`,
+		`{"id":"9bZkp7q19f0","url":"9bZkp7q19f0","ie_key":"Youtube"}`,
+		`{"id":"76979871","url":"https://vimeo.com/76979871","ie_key":"Vimeo"}`,
+		`{"id":"x8abc","webpage_url":"https://www.dailymotion.com/video/x8abc","url":"x8abc","extractor_key":"Dailymotion"}`,
+		`{"id":"lost","url":"lost","ie_key":"Generic"}`,
+		`WARNING: not json`,
+		``,
+	}, "\n")
+	f := useRunner(t, func(args []string) fakeRun { return fakeRun{stdout: entries} })
+
+	urls, err := ExpandPlaylist("https://vimeo.com/showcase/123", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		"https://www.youtube.com/watch?v=9bZkp7q19f0",
+		"https://vimeo.com/76979871",
+		"https://www.dailymotion.com/video/x8abc",
+	}
+	if !slices.Equal(urls, want) {
+		t.Errorf("ExpandPlaylist = %q, want %q", urls, want)
+	}
+	if args := f.calls[0]; !hasRun(args, []string{"--flat-playlist", "--dump-json"}) || !hasRun(args, []string{"--playlist-end", "50"}) {
+		t.Errorf("args = %q", args)
+	}
+}
+
+func TestExpandPlaylistItemsAndLimit(t *testing.T) {
+	var lines []string
+	for i := range MaxPlaylistEntries + 10 {
+		lines = append(lines, fmt.Sprintf(`{"id":"video%06d","ie_key":"Youtube"}`, i))
+	}
+	f := useRunner(t, func(args []string) fakeRun { return fakeRun{stdout: strings.Join(lines, "\n")} })
+
+	urls, err := ExpandPlaylist("https://www.youtube.com/playlist?list=PL123", Options{PlaylistItems: "1-3, 7"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urls) != MaxPlaylistEntries {
+		t.Errorf("%d URLs, want the %d cap", len(urls), MaxPlaylistEntries)
+	}
+	if args := f.calls[0]; !hasRun(args, []string{"--playlist-items", "1-3,7"}) || slices.Contains(args, "--playlist-end") {
+		t.Errorf("args = %q, want the item selection instead of the end cap", args)
+	}
+}
+
+func TestExpandPlaylistEmpty(t *testing.T) {
+	useRunner(t, func(args []string) fakeRun { return fakeRun{stdout: `{"id":"x","url":"x","ie_key":"Generic"}`} })
+
+	if _, err := ExpandPlaylist("https://example.com/list", Options{}); err == nil {
+		t.Error("a playlist without usable entries expanded")
+	}
+}
@@ -0,0 +1,40 @@
+package downloader
+
+// DefaultMaxConcurrent is how many downloads run at once unless configured otherwise
+const DefaultMaxConcurrent = 3
+
+// Pool limits how many yt-dlp downloads run at the same time
+type Pool struct {
+	slots chan struct{}
+}
+
+// NewPool creates a pool with room for max concurrent downloads
+func NewPool(max int) *Pool {
+	if max < 1 {
+		max = DefaultMaxConcurrent
+	}
+	return &Pool{slots: make(chan struct{}, max)}
+}
+
+// TryAcquire claims a slot if one is free
+func (p *Pool) TryAcquire() bool {
+	select {
+	case p.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release frees a slot claimed by TryAcquire
+func (p *Pool) Release() {
+	select {
+	case <-p.slots:
+	default:
+	}
+}
+
+// Active returns the number of claimed slots
+func (p *Pool) Active() int {
+	return len(p.slots)
+}
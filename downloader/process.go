@@ -0,0 +1,94 @@
+package downloader
+
+import (
+	"errors"
+	"os/exec"
+	"sync"
+)
+
+// ErrPauseUnsupported is returned by PauseDownload where processes can't be suspended
+var ErrPauseUnsupported = errors.New("pausing downloads is not supported on this platform")
+
+// Process tracks the yt-dlp process behind a download so it can be paused.
+// A paused Process also suspends retry attempts started while it is paused.
+type Process struct {
+	mu     sync.Mutex
+	cmd    *exec.Cmd // running attempt, nil between attempts
+	paused bool
+}
+
+// attach records a freshly started attempt, suspending it straight away
+// if the download was paused in the meantime
+func (p *Process) attach(cmd *exec.Cmd) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.cmd = cmd
+	if p.paused {
+		stopProcess(cmd)
+	}
+}
+
+// detach forgets an attempt once it has exited
+func (p *Process) detach() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.cmd = nil
+	p.mu.Unlock()
+}
+
+// KillDownload terminates the running attempt behind p right away, rather
+// than waiting for its context's cancellation to be noticed
+func KillDownload(p *Process) error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cmd == nil || p.cmd.Cancel == nil {
+		return nil
+	}
+	return p.cmd.Cancel()
+}
+
+// PauseDownload suspends the yt-dlp process (and its ffmpeg children) behind p
+func PauseDownload(p *Process) error {
+	if !pauseSupported {
+		return ErrPauseUnsupported
+	}
+	if p == nil {
+		return errors.New("download is not running")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.paused = true
+	if p.cmd == nil {
+		return nil
+	}
+	return stopProcess(p.cmd)
+}
+
+// ResumeDownload continues a download suspended by PauseDownload
+func ResumeDownload(p *Process) error {
+	if !pauseSupported {
+		return ErrPauseUnsupported
+	}
+	if p == nil {
+		return errors.New("download is not running")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.paused = false
+	if p.cmd == nil {
+		return nil
+	}
+	return continueProcess(p.cmd)
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+
+package downloader
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+const pauseSupported = true
+
+// setProcessGroup puts yt-dlp in its own process group so pausing and
+// cancelling also reach the ffmpeg processes it spawns
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+func stopProcess(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGSTOP)
+}
+
+func continueProcess(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGCONT)
+}
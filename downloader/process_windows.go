@@ -0,0 +1,17 @@
+//go:build windows
+
+package downloader
+
+import "os/exec"
+
+const pauseSupported = false
+
+func setProcessGroup(*exec.Cmd) {}
+
+func stopProcess(*exec.Cmd) error {
+	return ErrPauseUnsupported
+}
+
+func continueProcess(*exec.Cmd) error {
+	return ErrPauseUnsupported
+}
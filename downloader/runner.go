@@ -0,0 +1,96 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+)
+
+// CommandRunner starts the external tools the downloader drives. Swap
+// Runner for a fake to replay canned yt-dlp output without the real binary.
+type CommandRunner interface {
+	// Run starts name with args. Both streams must be read to EOF before
+	// calling wait, which reports how the command exited.
+	Run(ctx context.Context, name string, args ...string) (stdout, stderr io.Reader, wait func() error, err error)
+}
+
+// Runner is used by every downloader function that runs yt-dlp or ffmpeg
+var Runner CommandRunner = ExecRunner{}
+
+// ExecRunner runs real processes. Cancelling ctx kills the process and
+// everything it spawned, and a Process attached with withProcess can pause it.
+type ExecRunner struct{}
+
+func (ExecRunner) Run(ctx context.Context, name string, args ...string) (io.Reader, io.Reader, func() error, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	setProcessGroup(cmd)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("creating stdout pipe: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("creating stderr pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	p := processFrom(ctx)
+	p.attach(cmd)
+	wait := func() error {
+		defer p.detach()
+		return cmd.Wait()
+	}
+	return stdout, stderr, wait, nil
+}
+
+type processKey struct{}
+
+// withProcess makes ExecRunner report the processes it starts for ctx to p
+func withProcess(ctx context.Context, p *Process) context.Context {
+	if p == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, processKey{}, p)
+}
+
+// processFrom returns the Process attached by withProcess, or nil
+func processFrom(ctx context.Context) *Process {
+	p, _ := ctx.Value(processKey{}).(*Process)
+	return p
+}
+
+// runOutput runs a command through Runner and returns everything it
+// printed on stdout
+func runOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	stdout, stderr, wait, err := Runner.Run(ctx, name, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// drain stderr alongside stdout so a chatty command can't block on a
+	// full pipe, keeping it so a failure can say what went wrong
+	var errOut bytes.Buffer
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		io.Copy(&errOut, stderr)
+	}()
+
+	var out bytes.Buffer
+	_, readErr := io.Copy(&out, stdout)
+	wg.Wait()
+	if err := wait(); err != nil {
+		if message := lastErrorLine(errOut.String()); message != "" {
+			return out.Bytes(), newDownloadError(message, err)
+		}
+		return out.Bytes(), err
+	}
+	return out.Bytes(), readErr
+}
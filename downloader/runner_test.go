@@ -0,0 +1,511 @@
+package downloader
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRun is the canned result of one command
+type fakeRun struct {
+	stdout string
+	stderr string
+	err    error // returned by wait
+}
+
+// fakeRunner answers every command from a script instead of running it
+type fakeRunner struct {
+	mu     sync.Mutex
+	calls  [][]string
+	script func(args []string) fakeRun
+}
+
+func (f *fakeRunner) Run(ctx context.Context, name string, args ...string) (io.Reader, io.Reader, func() error, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, args)
+	run := f.script(args)
+	f.mu.Unlock()
+	return strings.NewReader(run.stdout), strings.NewReader(run.stderr), func() error { return run.err }, nil
+}
+
+// downloads returns the arguments of every call that wasn't a metadata lookup
+func (f *fakeRunner) downloads() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var calls [][]string
+	for _, args := range f.calls {
+		if !isDumpJSON(args) {
+			calls = append(calls, args)
+		}
+	}
+	return calls
+}
+
+// useRunner swaps Runner for a fake driven by script until the test ends
+func useRunner(t *testing.T, script func(args []string) fakeRun) *fakeRunner {
+	t.Helper()
+	f := &fakeRunner{script: script}
+	old := Runner
+	Runner = f
+	t.Cleanup(func() { Runner = old })
+	return f
+}
+
+func isDumpJSON(args []string) bool {
+	return slices.Contains(args, "--dump-json")
+}
+
+// exitFailure is what wait returns for a yt-dlp that exited non-zero
+func exitFailure() error {
+	return &exec.ExitError{}
+}
+
+// fastRetries shrinks the backoff so retry tests don't sleep
+func fastRetries(t *testing.T) {
+	t.Helper()
+	old := DefaultRetryPolicy
+	DefaultRetryPolicy = RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond}
+	t.Cleanup(func() { DefaultRetryPolicy = old })
+}
+
+// collect reads ch until it is closed, failing the test if that takes too long
+func collect(t *testing.T, ch <-chan ProgressFractionMsg) []ProgressFractionMsg {
+	t.Helper()
+	var msgs []ProgressFractionMsg
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				return msgs
+			}
+			msgs = append(msgs, msg)
+		case <-timeout:
+			t.Fatal("progress channel was never closed")
+		}
+	}
+}
+
+const videoJSON = `{"title":"Test Video","duration":212,"resolution":"1920x1080","width":1920,"height":1080,"fps":30,"vbr":4000,"abr":128,"tbr":4128,"filesize_approx":52428800,"extractor_key":"Youtube","channel":"Test Channel"}`
+
+func TestFetchVideoInfo(t *testing.T) {
+	f := useRunner(t, func(args []string) fakeRun { return fakeRun{stdout: videoJSON} })
+
+	info, err := FetchVideoInfo("https://youtu.be/dQw4w9WgXcQ", Options{Format: "mp4", MaxHeight: 1080})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := VideoInfo{
+		URL:        "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		Title:      "Test Video",
+		Duration:   212,
+		Resolution: "1920x1080",
+		Width:      1920,
+		Height:     1080,
+		FPS:        30,
+		VBR:        4000,
+		ABR:        128,
+		TBR:        4128,
+		Filesize:   52428800,
+		Extractor:  "Youtube",
+		Channel:    "Test Channel",
+		Format:     "mp4",
+	}
+	if got := info; got.URL != want.URL || got.Title != want.Title || got.Duration != want.Duration ||
+		got.Resolution != want.Resolution || got.Width != want.Width || got.Height != want.Height ||
+		got.FPS != want.FPS || got.VBR != want.VBR || got.ABR != want.ABR || got.TBR != want.TBR ||
+		got.Filesize != want.Filesize || got.Extractor != want.Extractor || got.Channel != want.Channel ||
+		got.Format != want.Format {
+		t.Errorf("FetchVideoInfo =\n%+v\nwant\n%+v", got, want)
+	}
+
+	args := f.calls[0]
+	if i := slices.Index(args, "-f"); i < 0 || args[i+1] != "bestvideo[height<=1080]+bestaudio/best[height<=1080]/best" {
+		t.Errorf("metadata lookup args %q don't select the download's streams", args)
+	}
+}
+
+func TestFetchVideoInfoErrors(t *testing.T) {
+	useRunner(t, func(args []string) fakeRun {
+		return fakeRun{stderr: "ERROR: [youtube] x: Private video. Sign in if you've been granted access\n", err: exitFailure()}
+	})
+
+	_, err := FetchVideoInfo("https://www.youtube.com/watch?v=x", Options{})
+	if err == nil || !strings.Contains(err.Error(), "Private video") {
+		t.Errorf("FetchVideoInfo error = %v, want yt-dlp's reason", err)
+	}
+}
+
+func TestDownloadRetriesTransientFailures(t *testing.T) {
+	fastRetries(t)
+	t.Chdir(t.TempDir())
+
+	attempts := 0
+	f := useRunner(t, func(args []string) fakeRun {
+		if isDumpJSON(args) {
+			return fakeRun{stdout: videoJSON}
+		}
+		attempts++
+		if attempts < 3 {
+			return fakeRun{stderr: "ERROR: Unable to download webpage: connection reset\n", err: exitFailure()}
+		}
+		return fakeRun{stdout: "[download] Destination: Test Video.mp4\n[download] 100% of 1.00MiB\n"}
+	})
+
+	ch := make(chan ProgressFractionMsg, 50)
+	DownloadStreamWithProgress(context.Background(), "https://example.com/v", Options{Format: "mp4"}, ch)
+	msgs := collect(t, ch)
+
+	if final := msgs[len(msgs)-1]; final.Err != nil {
+		t.Fatalf("download failed after retries: %v", final.Err)
+	}
+	calls := f.downloads()
+	if len(calls) != 3 {
+		t.Fatalf("%d download attempts, want 3", len(calls))
+	}
+	if slices.Contains(calls[0], "--continue") {
+		t.Error("first attempt already resumes")
+	}
+	for i, args := range calls[1:] {
+		if !slices.Contains(args, "--continue") {
+			t.Errorf("retry %d doesn't resume the partial file: %q", i+1, args)
+		}
+	}
+	retries := 0
+	for _, msg := range msgs {
+		if strings.HasPrefix(msg.Line, "↻ RETRY") {
+			retries++
+		}
+	}
+	if retries != 2 {
+		t.Errorf("%d retry lines reported, want 2", retries)
+	}
+}
+
+func TestDownloadGivesUpOnPermanentFailures(t *testing.T) {
+	fastRetries(t)
+
+	f := useRunner(t, func(args []string) fakeRun {
+		return fakeRun{stderr: "ERROR: [youtube] x: Video unavailable. This video has been removed by the uploader\n", err: exitFailure()}
+	})
+
+	ch := make(chan ProgressFractionMsg, 50)
+	DownloadStreamWithProgress(context.Background(), "https://example.com/v", Options{Format: "mp4"}, ch)
+	msgs := collect(t, ch)
+
+	final := msgs[len(msgs)-1]
+	dlErr, ok := final.Err.(*DownloadError)
+	if !ok || dlErr.Reason != "REMOVED" {
+		t.Fatalf("final error = %v, want a REMOVED DownloadError", final.Err)
+	}
+	if n := len(f.downloads()); n != 1 {
+		t.Errorf("%d attempts for a permanent failure, want 1", n)
+	}
+}
+
+func TestDownloadRetriesGivesUpAfterMaxRetries(t *testing.T) {
+	fastRetries(t)
+
+	f := useRunner(t, func(args []string) fakeRun {
+		return fakeRun{stderr: "ERROR: HTTP Error 429: Too Many Requests\n", err: exitFailure()}
+	})
+
+	ch := make(chan ProgressFractionMsg, 50)
+	DownloadStreamWithProgress(context.Background(), "https://example.com/v", Options{Format: "mp4"}, ch)
+	msgs := collect(t, ch)
+
+	if final := msgs[len(msgs)-1]; final.Err == nil {
+		t.Fatal("download succeeded, want the rate limit error")
+	}
+	if n := len(f.downloads()); n != 1+DefaultRetryPolicy.MaxRetries {
+		t.Errorf("%d attempts, want %d", n, 1+DefaultRetryPolicy.MaxRetries)
+	}
+}
+
+func TestDownloadFallsBackToBestFormat(t *testing.T) {
+	fastRetries(t)
+	t.Chdir(t.TempDir())
+
+	f := useRunner(t, func(args []string) fakeRun {
+		if isDumpJSON(args) {
+			return fakeRun{stdout: videoJSON}
+		}
+		if i := slices.Index(args, "-f"); args[i+1] != fallbackFormat {
+			return fakeRun{stderr: "ERROR: [youtube] x: Requested format is not available. Use --list-formats for a list of available formats\n", err: exitFailure()}
+		}
+		return fakeRun{stdout: "[download] Destination: " + filepath.Join(".", "Test Video.mp4") + "\n"}
+	})
+
+	ch := make(chan ProgressFractionMsg, 50)
+	DownloadStreamWithProgress(context.Background(), "https://example.com/v", Options{Format: "mp4", FormatID: "137+140"}, ch)
+	msgs := collect(t, ch)
+
+	if final := msgs[len(msgs)-1]; final.Err != nil {
+		t.Fatalf("fallback download failed: %v", final.Err)
+	}
+	calls := f.downloads()
+	if len(calls) != 2 {
+		t.Fatalf("%d download attempts, want the original and one fallback", len(calls))
+	}
+	if i := slices.Index(calls[1], "-f"); calls[1][i+1] != fallbackFormat {
+		t.Errorf("fallback attempt selected %q, want %q", calls[1][i+1], fallbackFormat)
+	}
+	if !slices.ContainsFunc(msgs, func(m ProgressFractionMsg) bool { return strings.Contains(m.Line, "RETRYING WITH BEST") }) {
+		t.Error("fallback wasn't reported in the progress lines")
+	}
+}
+
+func TestDownloadRenamesOnFileNameCollision(t *testing.T) {
+	fastRetries(t)
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	taken := filepath.Join(dir, "Test Video.mp4")
+	if err := writeHistory("downloads.json", []VideoInfo{{URL: "https://example.com/other", Title: "Other Video", FilePath: taken}}); err != nil {
+		t.Fatal(err)
+	}
+
+	f := useRunner(t, func(args []string) fakeRun {
+		if isDumpJSON(args) {
+			return fakeRun{stdout: videoJSON}
+		}
+		name := "Test Video.mp4"
+		if slices.Contains(args, withID(DefaultOutputTemplate, Options{UniqueName: true})) {
+			name = "Test Video [abc123].mp4"
+		}
+		return fakeRun{stdout: "[download] Destination: " + name + "\n"}
+	})
+
+	ch := make(chan ProgressFractionMsg, 50)
+	DownloadStreamWithProgress(context.Background(), "https://example.com/v", Options{Format: "mp4", HistoryPath: "downloads.json"}, ch)
+	msgs := collect(t, ch)
+
+	if final := msgs[len(msgs)-1]; final.Err != nil {
+		t.Fatalf("download failed: %v", final.Err)
+	}
+	calls := f.downloads()
+	if len(calls) != 2 || !slices.Contains(calls[1], "%(title)s [%(id)s].%(ext)s") {
+		t.Fatalf("download calls = %q, want a retry with the id-suffixed name", calls)
+	}
+
+	infos, err := LoadHistory("downloads.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("%d records, want the other video's and the new one", len(infos))
+	}
+	got := infos[1]
+	if got.FilePath != filepath.Join(dir, "Test Video [abc123].mp4") || !got.UniqueName {
+		t.Errorf("record FilePath %q, UniqueName %v, want the id-suffixed file", got.FilePath, got.UniqueName)
+	}
+	if infos[0].FilePath != taken {
+		t.Error("the other video's record was touched")
+	}
+}
+
+func TestDownloadKeepsOwnFileName(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	// the same video downloaded again may reuse its own file
+	if err := writeHistory("downloads.json", []VideoInfo{{URL: "https://example.com/v", FilePath: filepath.Join(dir, "Test Video.mp4")}}); err != nil {
+		t.Fatal(err)
+	}
+	f := useRunner(t, func(args []string) fakeRun {
+		if isDumpJSON(args) {
+			return fakeRun{stdout: videoJSON}
+		}
+		return fakeRun{stdout: "[download] Destination: Test Video.mp4\n"}
+	})
+
+	ch := make(chan ProgressFractionMsg, 50)
+	DownloadStreamWithProgress(context.Background(), "https://example.com/v", Options{Format: "mp4", HistoryPath: "downloads.json"}, ch)
+	collect(t, ch)
+
+	if n := len(f.downloads()); n != 1 {
+		t.Errorf("%d download attempts, want no rename retry", n)
+	}
+}
+
+// selector returns the -f value of a yt-dlp call
+func selector(args []string) string {
+	if i := slices.Index(args, "-f"); i >= 0 && i+1 < len(args) {
+		return args[i+1]
+	}
+	return ""
+}
+
+func TestSavedRecordDescribesTheFallbackStreams(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	f := useRunner(t, func(args []string) fakeRun {
+		if isDumpJSON(args) {
+			if selector(args) == fallbackFormat {
+				return fakeRun{stdout: `{"title":"Test Video","resolution":"1280x720","width":1280,"height":720,"fps":30,"vbr":1500,"abr":96,"tbr":1596}`}
+			}
+			return fakeRun{stdout: videoJSON}
+		}
+		if selector(args) != fallbackFormat {
+			return fakeRun{stderr: "ERROR: [youtube] x: Requested format is not available\n", err: exitFailure()}
+		}
+		return fakeRun{stdout: "[download] Destination: Test Video.mp4\n"}
+	})
+
+	ch := make(chan ProgressFractionMsg, 50)
+	DownloadStreamWithProgress(context.Background(), "https://example.com/v", Options{Format: "mp4", FormatID: "137+140", HistoryPath: "downloads.json"}, ch)
+	collect(t, ch)
+
+	f.mu.Lock()
+	for _, args := range f.calls {
+		if isDumpJSON(args) && selector(args) != fallbackFormat {
+			t.Errorf("metadata looked up with -f %q, want the fallback's %q", selector(args), fallbackFormat)
+		}
+	}
+	f.mu.Unlock()
+
+	infos, err := LoadHistory("downloads.json")
+	if err != nil || len(infos) != 1 {
+		t.Fatalf("history = %v, %v, want one record", infos, err)
+	}
+	if got := infos[0]; got.Height != 720 || got.Resolution != "1280x720" || got.VBR != 1500 {
+		t.Errorf("record has %s (height %d, vbr %v), want the 720p fallback streams", got.Resolution, got.Height, got.VBR)
+	}
+}
+
+func TestSaveVideoInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		selector string
+		check    func(t *testing.T, info VideoInfo)
+	}{
+		{
+			name:     "capped mp4",
+			opts:     Options{Format: "mp4", MaxHeight: 1080},
+			selector: "bestvideo[height<=1080]+bestaudio/best[height<=1080]/best",
+			check: func(t *testing.T, info VideoInfo) {
+				if info.Height != 1080 || info.VBR != 4000 || info.Container != "mp4" {
+					t.Errorf("height %d, vbr %v, container %q, want the 1080p mp4 streams", info.Height, info.VBR, info.Container)
+				}
+			},
+		},
+		{
+			name:     "lossy audio",
+			opts:     Options{Format: "mp3", AudioQuality: "320K"},
+			selector: "bestaudio",
+			check: func(t *testing.T, info VideoInfo) {
+				if info.Width != 0 || info.Height != 0 || info.FPS != 0 || info.VBR != 0 {
+					t.Errorf("audio record kept video fields: %dx%d %dfps vbr %v", info.Width, info.Height, info.FPS, info.VBR)
+				}
+				if info.Resolution != "audio only" || info.ABR != 320 || info.TBR != 320 || info.AudioFormat != "mp3" {
+					t.Errorf("resolution %q, abr %v, tbr %v, codec %q, want 320K mp3 audio", info.Resolution, info.ABR, info.TBR, info.AudioFormat)
+				}
+			},
+		},
+		{
+			name:     "lossless audio",
+			opts:     Options{Format: "mp3", AudioFormat: "flac", AudioQuality: "320K"},
+			selector: "bestaudio",
+			check: func(t *testing.T, info VideoInfo) {
+				if info.Height != 0 || info.ABR != 128 || info.AudioFormat != "flac" {
+					t.Errorf("height %d, abr %v, codec %q, want flac at the source bitrate", info.Height, info.ABR, info.AudioFormat)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			f := useRunner(t, func(args []string) fakeRun { return fakeRun{stdout: videoJSON} })
+
+			file := filepath.Join(dir, "Test Video."+container(tt.opts))
+			if tt.opts.Format == "mp3" {
+				file = filepath.Join(dir, "Test Video."+audioFormat(tt.opts))
+			}
+			if err := os.WriteFile(file, make([]byte, 1234), 0644); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, "downloads.json")
+			saveVideoInfo("https://example.com/v", tt.opts, file, nil, path)
+
+			if got := selector(f.calls[0]); got != tt.selector {
+				t.Errorf("metadata looked up with -f %q, want %q", got, tt.selector)
+			}
+			infos, err := LoadHistory(path)
+			if err != nil || len(infos) != 1 {
+				t.Fatalf("history = %v, %v, want one record", infos, err)
+			}
+			if infos[0].Filesize != 1234 {
+				t.Errorf("Filesize = %d, want the size on disk", infos[0].Filesize)
+			}
+			tt.check(t, infos[0])
+		})
+	}
+}
+
+func TestTitleOrFallback(t *testing.T) {
+	const url = "https://www.youtube.com/watch?v=dQw4w9WgXcQ"
+	tests := []struct {
+		printed string
+		want    string
+	}{
+		{"Never Gonna Give You Up", "Never Gonna Give You Up"},
+		{"  Padded Title \r", "Padded Title"},
+		{"", "YouTube Video: dQw4w9WgXcQ"},
+		{"   ", "YouTube Video: dQw4w9WgXcQ"},
+		{"NA", "YouTube Video: dQw4w9WgXcQ"},
+		{"NA ", "YouTube Video: dQw4w9WgXcQ"},
+		{"NATO summit", "NATO summit"},
+	}
+
+	for _, tt := range tests {
+		if got := titleOrFallback(tt.printed, url); got != tt.want {
+			t.Errorf("titleOrFallback(%q) = %q, want %q", tt.printed, got, tt.want)
+		}
+	}
+}
+
+func TestFetchTitleAsync(t *testing.T) {
+	const url = "https://www.youtube.com/watch?v=dQw4w9WgXcQ"
+	tests := []struct {
+		name       string
+		run        fakeRun
+		title      string
+		liveStatus string
+		wantErr    bool
+	}{
+		{"title", fakeRun{stdout: "Test Video\nnot_live\n"}, "Test Video", "not_live", false},
+		{"empty title", fakeRun{stdout: "\nis_upcoming\n"}, "YouTube Video: dQw4w9WgXcQ", "is_upcoming", false},
+		{"NA title", fakeRun{stdout: "NA\nNA\n"}, "YouTube Video: dQw4w9WgXcQ", "", false},
+		{"failure", fakeRun{stderr: "ERROR: Private video\n", err: exitFailure()}, "YouTube Video: dQw4w9WgXcQ", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useRunner(t, func(args []string) fakeRun { return tt.run })
+
+			got := make(chan TitleFetchedMsg, 1)
+			FetchTitleAsync(url, Options{}, func(msg TitleFetchedMsg) { got <- msg })
+
+			select {
+			case msg := <-got:
+				if msg.Title != tt.title || msg.LiveStatus != tt.liveStatus || (msg.Error != nil) != tt.wantErr {
+					t.Errorf("got title %q, live status %q, error %v; want %q, %q, error %v", msg.Title, msg.LiveStatus, msg.Error, tt.title, tt.liveStatus, tt.wantErr)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("callback never ran")
+			}
+		})
+	}
+}
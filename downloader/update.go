@@ -0,0 +1,18 @@
+package downloader
+
+// CheckForUpdate reports whether the video behind an archived record is now
+// available in a better version (a higher resolution, or the same one at a
+// higher frame rate) under the limits in opts. It costs one --dump-json.
+// Audio extractions are re-encoded anyway, so they are never reported.
+func CheckForUpdate(info VideoInfo, opts Options) (bool, error) {
+	if info.Format == "mp3" || info.Height == 0 {
+		return false, nil
+	}
+
+	opts.Format = info.Format
+	fresh, err := FetchVideoInfo(info.URL, opts)
+	if err != nil {
+		return false, err
+	}
+	return fresh.Height > info.Height || fresh.Height == info.Height && fresh.FPS > info.FPS, nil
+}
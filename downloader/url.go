@@ -0,0 +1,123 @@
+package downloader
+
+import (
+	"net/url"
+	"strings"
+)
+
+// IsSupportedURL reports whether s looks like something yt-dlp can fetch:
+// an http(s) URL with a real host name.
+func IsSupportedURL(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, " \t\n") {
+		return false
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+
+	host := u.Hostname()
+	return host != "" && (strings.Contains(host, ".") || host == "localhost")
+}
+
+// trackingParams are query parameters that don't change which video a URL points at
+var trackingParams = []string{"si", "feature", "pp", "fbclid", "gclid", "igshid", "ab_channel"}
+
+// NormalizeURL strips tracking parameters and fragments so the same video
+// pasted from different places compares equal
+func NormalizeURL(s string) string {
+	s = CanonicalizeURL(s)
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return s
+	}
+
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+
+	q := u.Query()
+	for key := range q {
+		if strings.HasPrefix(key, "utm_") {
+			q.Del(key)
+		}
+	}
+	for _, key := range trackingParams {
+		q.Del(key)
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// youtubeHosts are the hosts CanonicalizeURL rewrites
+var youtubeHosts = map[string]bool{
+	"youtube.com":              true,
+	"www.youtube.com":          true,
+	"m.youtube.com":            true,
+	"music.youtube.com":        true,
+	"youtube-nocookie.com":     true,
+	"www.youtube-nocookie.com": true,
+	"youtu.be":                 true,
+}
+
+// youtubeIDPrefixes are path prefixes that are followed by a video ID
+var youtubeIDPrefixes = []string{"/shorts/", "/embed/", "/v/", "/live/"}
+
+// CanonicalizeURL rewrites any YouTube video link (short links, shorts,
+// embeds, watch URLs with playlist or timestamp params) to
+// https://www.youtube.com/watch?v=ID. Anything else is returned unchanged.
+func CanonicalizeURL(s string) string {
+	s = strings.TrimSpace(s)
+	u, err := url.Parse(s)
+	if err != nil || !youtubeHosts[strings.ToLower(u.Hostname())] {
+		return s
+	}
+
+	if id := youtubeVideoID(u); id != "" {
+		return "https://www.youtube.com/watch?v=" + id
+	}
+	return s
+}
+
+// youtubeVideoID pulls the video ID out of a YouTube URL, or returns ""
+// for links that don't point at a single video (channels, playlists)
+func youtubeVideoID(u *url.URL) string {
+	var id string
+	switch {
+	case strings.EqualFold(u.Hostname(), "youtu.be"):
+		id = strings.Trim(u.Path, "/")
+	case u.Path == "/watch":
+		id = u.Query().Get("v")
+	default:
+		for _, prefix := range youtubeIDPrefixes {
+			if strings.HasPrefix(u.Path, prefix) {
+				id = strings.TrimPrefix(u.Path, prefix)
+				break
+			}
+		}
+	}
+
+	id, _, _ = strings.Cut(id, "/")
+	if !validVideoID(id) {
+		return ""
+	}
+	return id
+}
+
+// validVideoID reports whether id only uses the characters YouTube IDs are made of
+func validVideoID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
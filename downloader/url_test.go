@@ -0,0 +1,69 @@
+package downloader
+
+import "testing"
+
+func TestIsSupportedURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", true},
+		{"http://youtu.be/dQw4w9WgXcQ", true},
+		{"https://vimeo.com/76979871", true},
+		{"http://localhost:8080/video.mp4", true},
+		{"  https://youtu.be/dQw4w9WgXcQ\n", true},
+		{"www.youtube.com/watch?v=dQw4w9WgXcQ", false},
+		{"youtu.be/dQw4w9WgXcQ", false},
+		{"ftp://example.com/video.mp4", false},
+		{"file:///home/me/video.mp4", false},
+		{"javascript:alert(1)", false},
+		{"https://", false},
+		{"https:///watch?v=dQw4w9WgXcQ", false},
+		{"https://intranet/video", false},
+		{"", false},
+		{"   ", false},
+		{"https://youtu.be/a b", false},
+		{"https://youtu.be/a\thttps://youtu.be/b", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsSupportedURL(tt.url); got != tt.want {
+			t.Errorf("IsSupportedURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalizeURL(t *testing.T) {
+	const canonical = "https://www.youtube.com/watch?v=dQw4w9WgXcQ"
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://youtu.be/dQw4w9WgXcQ", canonical},
+		{"https://youtu.be/dQw4w9WgXcQ?si=abc123&t=42", canonical},
+		{"https://m.youtube.com/watch?v=dQw4w9WgXcQ", canonical},
+		{"https://music.youtube.com/watch?v=dQw4w9WgXcQ&feature=share", canonical},
+		{"https://www.youtube.com/shorts/dQw4w9WgXcQ", canonical},
+		{"https://youtube.com/shorts/dQw4w9WgXcQ?feature=share", canonical},
+		{"https://www.youtube.com/embed/dQw4w9WgXcQ?start=10", canonical},
+		{"https://www.youtube-nocookie.com/embed/dQw4w9WgXcQ", canonical},
+		{"https://www.youtube.com/live/dQw4w9WgXcQ", canonical},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=1m30s", canonical},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&list=PL123&index=4&pp=xyz", canonical},
+		{"https://www.youtube.com/watch?feature=shared&v=dQw4w9WgXcQ", canonical},
+		{"  " + canonical + "  ", canonical},
+		// links that aren't a single video stay as they are
+		{"https://www.youtube.com/playlist?list=PL123", "https://www.youtube.com/playlist?list=PL123"},
+		{"https://www.youtube.com/@channel", "https://www.youtube.com/@channel"},
+		{"https://www.youtube.com/watch?v=not%20an%20id", "https://www.youtube.com/watch?v=not%20an%20id"},
+		{"https://vimeo.com/76979871?share=copy", "https://vimeo.com/76979871?share=copy"},
+		{"https://example.com/embed/dQw4w9WgXcQ", "https://example.com/embed/dQw4w9WgXcQ"},
+		{"not a url", "not a url"},
+	}
+
+	for _, tt := range tests {
+		if got := CanonicalizeURL(tt.url); got != tt.want {
+			t.Errorf("CanonicalizeURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package downloader
+
+import "os"
+
+// VerifyStatus is the outcome of checking one archived file
+type VerifyStatus int
+
+const (
+	VerifyOK           VerifyStatus = iota
+	VerifyMissing                   // no file at the recorded path
+	VerifySizeMismatch              // file is far from the recorded size, e.g. truncated
+)
+
+func (s VerifyStatus) String() string {
+	switch s {
+	case VerifyMissing:
+		return "MISSING"
+	case VerifySizeMismatch:
+		return "SIZE MISMATCH"
+	}
+	return "OK"
+}
+
+// VerifyResult is the integrity check of one archived case
+type VerifyResult struct {
+	Info   VideoInfo
+	Status VerifyStatus
+	Size   int64 // size on disk, 0 when missing
+}
+
+// sizeTolerance is how far a file may be from its recorded size before it
+// is flagged. Filesize is often yt-dlp's pre-download estimate, so an exact
+// match can't be expected.
+const sizeTolerance = 0.1
+
+// VerifyArchive checks that every archived file still exists and roughly
+// matches its recorded size. Only video archives are size-checked: audio
+// extractions and clips never match the size of the source stream.
+func VerifyArchive(infos []VideoInfo) []VerifyResult {
+	results := make([]VerifyResult, 0, len(infos))
+	for _, info := range infos {
+		result := VerifyResult{Info: info}
+
+		path := info.FilePath
+		if path == "" {
+			path = MediaPath(info)
+		}
+		stat, err := os.Stat(path)
+		switch {
+		case path == "" || err != nil || stat.IsDir():
+			result.Status = VerifyMissing
+		default:
+			result.Size = stat.Size()
+			if sizeChecked(info) && !withinTolerance(result.Size, info.Filesize) {
+				result.Status = VerifySizeMismatch
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// sizeChecked reports whether info's recorded size describes the archived file
+func sizeChecked(info VideoInfo) bool {
+	return info.Filesize > 0 && info.Format != "mp3" && info.ClipStart == ""
+}
+
+// withinTolerance reports whether size is within sizeTolerance of expected
+func withinTolerance(size, expected int64) bool {
+	diff := float64(size - expected)
+	return diff >= -sizeTolerance*float64(expected) && diff <= sizeTolerance*float64(expected)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+
+	"yeet-tube/config"
+	"yeet-tube/downloader"
+)
+
+// runHeadless downloads url without starting the TUI, printing progress to
+// stdout. It returns the process exit code.
+func runHeadless(cfg *config.Config, url, format string) int {
+	if !downloader.ValidFormat(format) {
+		fmt.Fprintf(os.Stderr, "unknown format %q (want mp4, mkv or mp3)\n", format)
+		return 2
+	}
+	if !downloader.IsSupportedURL(url) {
+		fmt.Fprintf(os.Stderr, "not a valid http(s) URL: %s\n", url)
+		return 2
+	}
+
+	noFFmpeg, err := checkHeadlessDeps(format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts, _ := headlessOptions(cfg, format, noFFmpeg)
+	if err := downloadOne(ctx, downloader.CanonicalizeURL(url), opts, ""); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// checkHeadlessDeps reports whether ffmpeg is missing, failing outright when
+// yt-dlp is missing or format needs ffmpeg
+func checkHeadlessDeps(format string) (noFFmpeg bool, err error) {
+	var missing *downloader.MissingDependenciesError
+	if err := downloader.CheckDependencies(); errors.As(err, &missing) {
+		if missing.Has("yt-dlp") {
+			return false, err
+		}
+		if format != "mp4" {
+			return false, fmt.Errorf("%s output requires ffmpeg", format)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// headlessOptions builds download options from the user's config, also
+// returning how many downloads may run at once
+func headlessOptions(cfg *config.Config, format string, noFFmpeg bool) (downloader.Options, int) {
+	if cfg.RateLimit != "" && !downloader.ValidRateLimit(cfg.RateLimit) {
+		fmt.Fprintf(os.Stderr, "ignoring invalid rate limit %q (use e.g. 500K or 2M)\n", cfg.RateLimit)
+	}
+	if cfg.Proxy != "" && !downloader.ValidProxy(cfg.Proxy) {
+		fmt.Fprintf(os.Stderr, "ignoring invalid proxy %q (use e.g. http://host:3128)\n", cfg.Proxy)
+	}
+	if items := cfg.PlaylistRange(); items != "" && downloader.ValidatePlaylistItems(items) != nil {
+		fmt.Fprintf(os.Stderr, "ignoring invalid playlist range %q (use e.g. 1-10,15)\n", items)
+	}
+
+	return downloader.Options{
+		Format:       format,
+		MaxHeight:    cfg.MaxHeight,
+		AudioFormat:  cfg.AudioFormat,
+		AudioQuality: cfg.AudioQuality,
+		EmbedArt:     cfg.EmbedArt,
+		DownloadSubs: cfg.DownloadSubs,
+		SubLangs:     cfg.SubLangs,
+
+		WriteThumbnail: cfg.Thumbnails,
+		WriteMetadata:  cfg.WriteMetadataFiles,
+		HistoryPath:    cfg.HistoryPath,
+		MaxHistory:     cfg.MaxHistory,
+
+		CookiesFromBrowser: cfg.CookiesFromBrowser,
+		NoFFmpeg:           noFFmpeg,
+		OutputTemplate:     cfg.OutputTemplate,
+		OutputDir:          cfg.OutputDir,
+		RateLimit:          cfg.RateLimit,
+		Proxy:              cfg.Proxy,
+		LiveFromStart:      cfg.LiveFromStart,
+		SplitChapters:      cfg.SplitChapters,
+		ExtraArgs:          cfg.ExtraArgs,
+		PlaylistItems:      cfg.PlaylistRange(),
+
+		ConcurrentFragments: cfg.ConcurrentFragments,
+	}, cfg.MaxConcurrent
+}
+
+// downloadOne runs a single download to completion, echoing yt-dlp output
+// with label in front of every line
+func downloadOne(ctx context.Context, url string, opts downloader.Options, label string) error {
+	// yt-dlp never finishes a live stream it isn't recording from the start
+	live, err := downloader.IsLive(url)
+	if errors.Is(err, downloader.ErrScheduled) {
+		return fmt.Errorf("%s: %w", url, err)
+	}
+	if live && !opts.LiveFromStart {
+		return fmt.Errorf("%s: live stream (set live_from_start in the config to record it)", url)
+	}
+
+	progress := make(chan downloader.ProgressFractionMsg, 50)
+	downloader.DownloadStreamWithProgress(ctx, url, opts, progress)
+
+	for msg := range progress {
+		switch {
+		case msg.Done && errors.Is(msg.Err, context.Canceled):
+			return fmt.Errorf("%s: cancelled", url)
+		case msg.Done && msg.Err != nil:
+			return fmt.Errorf("%s: %w", url, msg.Err)
+		case msg.Done:
+			return nil
+		case msg.Fraction >= 0 && msg.Fraction < 1:
+			fmt.Printf("%s[%5.1f%%] %s\n", label, msg.Fraction*100, msg.Line)
+		default:
+			fmt.Println(label + msg.Line)
+		}
+	}
+	return nil
+}
+
+// runBatch downloads every URL listed in r (one per line) without the TUI,
+// running up to the configured number at once. Blank lines and lines
+// starting with # are skipped. It returns the process exit code.
+func runBatch(cfg *config.Config, r io.Reader, format string) int {
+	if !downloader.ValidFormat(format) {
+		fmt.Fprintf(os.Stderr, "unknown format %q (want mp4, mkv or mp3)\n", format)
+		return 2
+	}
+
+	urls, err := readBatch(r)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error reading URL list:", err)
+		return 1
+	}
+	if len(urls) == 0 {
+		fmt.Fprintln(os.Stderr, "no URLs to download")
+		return 2
+	}
+
+	noFFmpeg, err := checkHeadlessDeps(format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts, workers := headlessOptions(cfg, format, noFFmpeg)
+	if workers <= 0 {
+		workers = downloader.DefaultMaxConcurrent
+	}
+
+	jobs := make(chan int)
+	errs := make([]error, len(urls))
+
+	var wg sync.WaitGroup
+	for range min(workers, len(urls)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				label := fmt.Sprintf("[%d/%d] ", i+1, len(urls))
+				errs[i] = downloadOne(ctx, downloader.CanonicalizeURL(urls[i]), opts, label)
+			}
+		}()
+	}
+
+	for i := range urls {
+		if !downloader.IsSupportedURL(urls[i]) {
+			errs[i] = fmt.Errorf("%s: not a valid http(s) URL", urls[i])
+			continue
+		}
+		if ctx.Err() != nil {
+			errs[i] = fmt.Errorf("%s: cancelled", urls[i])
+			continue
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+
+	fmt.Printf("\n%d/%d downloaded, %d failed\n", len(urls)-failed, len(urls), failed)
+	for _, err := range errs {
+		if err != nil {
+			fmt.Println("  ✖", err)
+		}
+	}
+
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// readBatch collects the lines of a batch list, skipping blanks and # comments
+func readBatch(r io.Reader) ([]string, error) {
+	var urls []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	return urls, scanner.Err()
+}
@@ -1,22 +1,89 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"yeet-tube/config"
+	"yeet-tube/downloader"
 	"yeet-tube/tui"
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run parses flags and starts either the TUI or a headless download,
+// returning the exit code so deferred cleanup still happens
+func run() int {
+	debug := flag.Bool("debug", false, "write all yt-dlp output to yeet-tube.log")
+	url := flag.String("url", "", "download this URL without the TUI and exit")
+	format := flag.String("format", "", "output format for -url and -batch: mp4, mkv (remux only) or mp3 (default from config)")
+	batch := flag.Bool("batch", false, "download URLs listed one per line in the given file (or stdin) and exit")
+	playlist := flag.Bool("playlist", false, "with -url, download the whole playlist a watch URL with &list= belongs to instead of just the video")
+	flag.Parse()
+
+	if *debug {
+		logFile, err := downloader.EnableDebugLog("yeet-tube.log")
+		if err != nil {
+			fmt.Printf("Error opening debug log: %v\n", err)
+			return 1
+		}
+		defer logFile.Close()
+	}
+
+	// Precedence: built-in defaults, then the config file, then YEET_* env vars, then flags
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		fmt.Printf("Error reading %s, using defaults: %v\n", config.DefaultPath(), err)
+	}
+	cfg.ApplyEnv()
+	if cfg.MaxPlaylistEntries > 0 {
+		downloader.MaxPlaylistEntries = cfg.MaxPlaylistEntries
+	}
+	// A missing default yt-dlp is reported by the dependency check instead
+	if cfg.YtDlpPath != "" && cfg.YtDlpPath != "yt-dlp" {
+		if err := downloader.SetYtDlpPath(cfg.YtDlpPath); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return 1
+		}
+	}
+	if *format == "" {
+		*format = cfg.Format
+	}
+
+	if *url != "" {
+		if *playlist {
+			*url = downloader.PlaylistURL(*url)
+		}
+		return runHeadless(cfg, *url, *format)
+	}
+	if *batch {
+		if flag.NArg() == 0 {
+			return runBatch(cfg, os.Stdin, *format)
+		}
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			fmt.Printf("Error opening URL list: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		return runBatch(cfg, f, *format)
+	}
+
 	p := tea.NewProgram(
-		tui.InitialModel(),
+		tui.InitialModel(cfg),
 		tea.WithAltScreen(), // <-- enable full-screen / alternate buffer
 		tea.WithMouseCellMotion(),
 	)
 
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	tui.Shutdown(final)
+	if err != nil {
 		fmt.Printf("Error running Yeet-Tube: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
+	return 0
 }
@@ -0,0 +1,36 @@
+package openutil
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// RevealInFolder opens the system file manager at the folder containing path,
+// selecting the file where the platform supports it
+func RevealInFolder(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", "-R", path)
+	case "windows":
+		cmd = exec.Command("explorer", "/select,", path)
+	default:
+		cmd = exec.Command("xdg-open", filepath.Dir(path))
+	}
+	return cmd.Start()
+}
+
+// Open opens path with the default application for its type
+func Open(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", "", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	return cmd.Start()
+}
@@ -1,16 +1,24 @@
 package tui
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
+	"yeet-tube/config"
 	"yeet-tube/downloader"
+	"yeet-tube/openutil"
 
 	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -20,60 +28,201 @@ type VideoDownload struct {
 	URL          string
 	Name         string
 	Percent      float64
-	Log          []string
+	Speed        string
+	ETA          string
+	ETASeconds   int                // ETA parsed to seconds, only meaningful while ETA is set
+	TotalBytes   int64              // size reported by yt-dlp, 0 until known
+	Options      downloader.Options // fixed at enqueue time
+	Log          []string           // last cfg.LogLines lines, shown under the selected case
+	FullLog      []string           // everything yt-dlp printed, shown in the log viewer
 	ProgressCh   chan downloader.ProgressFractionMsg
 	Done         bool
 	TitleFetched bool
+	ScanStarted  time.Time // when the title fetch began
+	Cancelled    bool
+	Failed       bool
+	Err          string                // failure reason reported by the downloader
+	Interrupted  bool                  // restored from a previous session, waiting to be resumed
+	Queued       bool                  // waiting for a free download slot
+	Paused       bool                  // suspended by the queue pause toggle
+	Pending      bool                  // dry run: metadata only, waiting to be started with "g"
+	Info         *downloader.VideoInfo // metadata collected by a dry run, nil until fetched
+	LastPercent  float64               // Percent when ProgressAt was taken
+	ProgressAt   time.Time             // when Percent last moved, for stall detection
+	Stalled      bool                  // no progress for cfg.StallTimeout
+	Cancel       context.CancelFunc    // kills this case's yt-dlp process
 }
 
 // Top-level TUI model
 type model struct {
 	textInput      textinput.Model
+	filterInput    textinput.Model // history search box, toggled by "/"
+	filtering      bool
+	sortMode       sortMode
+	sortQueueByETA bool           // render active cases soonest-finishing first
+	prompt         *prompt        // pending confirmation, captures the next key
+	logCase        *VideoDownload // case whose log viewer is open, nil when closed
+	logView        viewport.Model
+	depErr         error  // set while yt-dlp is missing; blocks new cases
+	ffmpegVersion  string // "" when ffmpeg is missing, which disables merging and mp3
 	status         string
 	videoQueue     []*VideoDownload
 	history        []downloader.VideoInfo
 	selectedIndex  int
+	queueIndex     int   // selected entry in videoQueue
+	focus          focus // pane that up/down moves through, cycled by tab
 	windowWidth    int
 	windowHeight   int
-	downloadFormat string // "mp4" or "mp3"
+	downloadFormat string // one of downloader.Formats
+	maxHeight      int    // video resolution cap
+	downloadSubs   bool
+	embedArt       bool   // mp3 music mode: embed thumbnail and metadata
+	audioQuality   string // mp3 bitrate
+	cfg            *config.Config
+	pool           *downloader.Pool
+	thumbCache     map[string]string              // rendered ASCII thumbnails by path and size
+	picker         *formatPicker                  // open format list, nil when closed
+	showHelp       bool                           // keybinding overlay, toggled by "?"
+	paused         bool                           // queue paused: nothing new starts, running cases are suspended
+	previewPending bool                           // preview shows the selected dry-run case instead of the archive
+	clipInput      *textinput.Model               // clip range being entered after alt+enter, nil otherwise
+	playlistPrompt *playlistPrompt                // items being picked from a pasted playlist, nil otherwise
+	tagInput       *textinput.Model               // tag being entered for the selected case, nil otherwise
+	theme          Theme                          // active color scheme, cycled by "t"
+	spinner        spinner.Model                  // shared by every case still fetching its title
+	undo           *deletion                      // last deleted case, restored by "u"
+	formatToggled  time.Time                      // when "m" last toggled the format, for the footer highlight
+	formatCache    map[string][]downloader.Format // ListFormats results by canonical URL
+	integrity      map[string]string              // problems found by "v", by recordKey
+	updates        map[string]bool                // watched records with a better format available, by recordKey
 }
 
 // Messages
 type tickMsg struct{}
 type titleFetchedMsg struct {
+	url        string
+	title      string
+	liveStatus string
+	err        error
+}
+type playlistExpandedMsg struct {
 	url   string
-	title string
+	urls  []string
 	err   error
+	force bool // skip duplicate checks
 }
-type setFormatMsg struct {
-	format string
+type clearStatusMsg struct {
+	status string
 }
 
-// Initialize model
-func InitialModel() model {
+// InitialModel builds the TUI around cfg, which main has already loaded
+// and overridden from the environment
+func InitialModel(cfg *config.Config) model {
 	ti := textinput.New()
 	ti.Placeholder = "ENTER TEMPORAL SEQUENCE CODE..."
 	ti.Focus()
-	ti.CharLimit = 256
-	ti.Width = 80
+	ti.CharLimit = maxURLLength
+	ti.Width = inputWidth(120)
+
+	fi := textinput.New()
+	fi.Placeholder = "FILTER ARCHIVE..."
+	fi.CharLimit = 128
+	fi.Width = 30
 
 	rand.Seed(time.Now().UnixNano())
 
-	return model{
+	if !downloader.ValidFormat(cfg.Format) {
+		cfg.Format = "mp4"
+	}
+	if cfg.HistoryPath == "" {
+		cfg.HistoryPath = downloader.DefaultHistoryPath
+	}
+	if !downloader.ValidOutputTemplate(cfg.OutputTemplate) {
+		cfg.OutputTemplate = downloader.DefaultOutputTemplate
+	}
+	if !downloader.ValidAudioQuality(cfg.AudioQuality) {
+		cfg.AudioQuality = downloader.DefaultAudioQuality
+	}
+	if !downloader.ValidAudioFormat(cfg.AudioFormat) {
+		cfg.AudioFormat = downloader.DefaultAudioFormat
+	}
+
+	status := readyStatus
+	if r := clampRatio(cfg.LeftPaneRatio, minLeftPaneRatio, maxLeftPaneRatio); r != cfg.LeftPaneRatio {
+		status = fmt.Sprintf("⚠ LEFT_PANE_RATIO MUST BE %.2f-%.2f • USING %.2f", minLeftPaneRatio, maxLeftPaneRatio, r)
+		cfg.LeftPaneRatio = r
+	}
+	if r := clampRatio(cfg.PreviewRatio, minPreviewRatio, maxPreviewRatio); r != cfg.PreviewRatio {
+		status = fmt.Sprintf("⚠ PREVIEW_RATIO MUST BE %.2f-%.2f • USING %.2f", minPreviewRatio, maxPreviewRatio, r)
+		cfg.PreviewRatio = r
+	}
+	if cfg.RateLimit != "" && !downloader.ValidRateLimit(cfg.RateLimit) {
+		status = "⚠ INVALID RATE LIMIT " + strings.ToUpper(cfg.RateLimit) + " IGNORED • USE E.G. 500K OR 2M"
+		cfg.RateLimit = ""
+	}
+	if cfg.Proxy != "" && !downloader.ValidProxy(cfg.Proxy) {
+		status = "⚠ INVALID PROXY " + cfg.Proxy + " IGNORED • USE E.G. HTTP://HOST:3128"
+		cfg.Proxy = ""
+	}
+	if !downloader.ValidConcurrentFragments(cfg.ConcurrentFragments) {
+		status = fmt.Sprintf("⚠ CONCURRENT FRAGMENTS MUST BE 1-%d • USING 1", downloader.MaxConcurrentFragments)
+		cfg.ConcurrentFragments = 1
+	}
+	if _, _, err := parseQuietHours(cfg.QuietHours); cfg.QuietHours != "" && err != nil {
+		status = "⚠ INVALID QUIET HOURS " + cfg.QuietHours + " IGNORED • USE E.G. 22:00-07:00"
+		cfg.QuietHours = ""
+	}
+	if items := cfg.PlaylistRange(); items != "" && downloader.ValidatePlaylistItems(items) != nil {
+		status = "⚠ INVALID PLAYLIST RANGE " + strings.ToUpper(items) + " IGNORED • ASKING PER PLAYLIST"
+		cfg.PlaylistStart, cfg.PlaylistEnd, cfg.PlaylistItems = 0, 0, ""
+	}
+	if cfg.TickRateMS < minTickRateMS || cfg.TickRateMS > maxTickRateMS {
+		status = fmt.Sprintf("⚠ TICK RATE MUST BE %d-%dMS • USING 100MS", minTickRateMS, maxTickRateMS)
+		cfg.TickRateMS = 100
+	}
+
+	queue := loadQueue(queuePath)
+	if len(queue) > 0 {
+		status = fmt.Sprintf("⚠ %d INTERRUPTED CASES RECOVERED • PRESS R TO RESUME", len(queue))
+	}
+
+	m := model{
 		textInput:      ti,
-		status:         "SYSTEM ONLINE • READY FOR VARIANT INGEST",
-		videoQueue:     []*VideoDownload{},
-		history:        loadHistory("downloads.json"),
+		filterInput:    fi,
+		status:         status,
+		videoQueue:     queue,
 		selectedIndex:  0,
 		windowWidth:    120,
 		windowHeight:   40,
-		downloadFormat: "mp4", // Default to mp4
+		downloadFormat: cfg.Format,
+		maxHeight:      cfg.MaxHeight,
+		downloadSubs:   cfg.DownloadSubs,
+		embedArt:       cfg.EmbedArt,
+		audioQuality:   cfg.AudioQuality,
+		cfg:            cfg,
+		theme:          themeByName(cfg.Theme),
+		spinner:        spinner.New(spinner.WithSpinner(spinner.MiniDot)),
+		pool:           downloader.NewPool(cfg.MaxConcurrent),
+		thumbCache:     map[string]string{},
+		formatCache:    map[string][]downloader.Format{},
+		updates:        map[string]bool{},
 	}
+	m.depErr, m.ffmpegVersion = checkDependencies()
+	if m.depErr == nil && m.ffmpegVersion == "" {
+		m.status = "⚠ FFMPEG NOT FOUND • MP3, MKV AND MERGED MP4 DISABLED, USING PRE-MERGED STREAMS"
+		m.downloadFormat = "mp4"
+	}
+	m.reloadHistory()
+	return m
 }
 
 // Init
 func (m model) Init() tea.Cmd {
-	return tickCmd()
+	cmds := []tea.Cmd{tickCmd(m.tickInterval()), m.checkWatched(false)}
+	if m.cfg.AutoFillFromClipboard {
+		cmds = append(cmds, clipboardURLCmd())
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update
@@ -84,6 +233,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.windowWidth = msg.Width
 		m.windowHeight = msg.Height
+		m.textInput.Width = inputWidth(m.windowWidth)
+		if m.logCase != nil {
+			m.logView.Width = max(m.windowWidth-4, 1)
+			m.logView.Height = max(m.windowHeight-6, 1)
+		}
 
 	case titleFetchedMsg:
 		for _, vd := range m.videoQueue {
@@ -96,108 +250,932 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					vd.Name = truncateString(strings.ToUpper(msg.url), 28)
 					vd.TitleFetched = true
 					if msg.err != nil {
-						m.status = "⚠ CASE IDENTIFICATION FAILED • USING RAW SEQUENCE"
+						m.status = "⚠ CASE IDENTIFICATION FAILED • " + failureReason(msg.err) + " • USING RAW SEQUENCE"
 					}
 				}
+				m.checkLive(vd, msg.liveStatus)
+				// start it now rather than on a tick that may still be on the idle interval
+				cmds = append(cmds, m.launchQueued()...)
 				break
 			}
 		}
 
+	case formatsListedMsg:
+		m.formatsListed(msg)
+
+	case clipboardURLMsg:
+		m.autoFill(msg.url)
+
+	case updatesCheckedMsg:
+		m.updatesChecked(msg)
+
+	case infoFetchedMsg:
+		m.infoFetched(msg)
+
+	case playlistExpandedMsg:
+		m.playlistExpanded(msg)
+
 	case tea.KeyMsg:
+		if m.depErr != nil {
+			return m.updateDeps(msg)
+		}
+		if m.prompt != nil {
+			return m.updatePrompt(msg)
+		}
+		if m.logCase != nil {
+			return m.updateLog(msg)
+		}
+		if m.picker != nil {
+			return m.updatePicker(msg)
+		}
+		if m.showHelp {
+			return m.updateHelp(msg)
+		}
+		if m.clipInput != nil {
+			return m.updateClip(msg)
+		}
+		if m.playlistPrompt != nil {
+			return m.updatePlaylistPrompt(msg)
+		}
+		if m.tagInput != nil {
+			return m.updateTag(msg)
+		}
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+		if msg.Paste {
+			m.setFocus(focusInput)
+			m.pasteURL(string(msg.Runes))
+			return m, tea.Batch(cmds...)
+		}
+
+		// Letter hotkeys only act while a list has focus (see hotkeys), and
+		// audio ones only in mp3 mode. Otherwise they break out of the
+		// switch and the key falls through to the URL box below, which
+		// ignores it unless it has focus. "?", "/" and "#" never start a
+		// URL, so they also work from an empty URL box.
 		switch msg.String() {
-		case "ctrl+c", "esc":
-			return m, tea.Quit
+		case "esc":
+			if m.filterInput.Value() != "" {
+				m.clearFilter()
+				return m, tea.Batch(cmds...)
+			}
+			return m.requestQuit()
+		case "ctrl+c":
+			return m.requestQuit()
+		case "?":
+			// "?" is part of most URLs, only treat it as a hotkey on an empty input
+			if m.textInput.Value() != "" {
+				break
+			}
+			m.showHelp = true
+			return m, tea.Batch(cmds...)
+		case "/":
+			if m.textInput.Value() != "" {
+				break
+			}
+			m.startFilter()
+			m.status = fmt.Sprintf("%d/%d CASES MATCH", len(m.visibleHistory()), len(m.history))
+			return m, tea.Batch(cmds...)
 		case "m":
-			if m.downloadFormat == "mp4" {
-				m.downloadFormat = "mp3"
+			if !m.hotkeys() {
+				break
+			}
+			if m.ffmpegVersion == "" {
+				m.status = "⚠ MP3 AND MKV REQUIRE FFMPEG • INSTALL IT TO ENABLE"
+				return m, tea.Batch(cmds...)
+			}
+			next := downloader.Formats[(slices.Index(downloader.Formats, m.downloadFormat)+1)%len(downloader.Formats)]
+			// applied right away so the footer always shows what the next enter uses
+			cmds = append(cmds, m.setFormat(next))
+			return m, tea.Batch(cmds...)
+		case "h":
+			if !m.hotkeys() {
+				break
+			}
+			m.maxHeight = nextResolution(m.maxHeight)
+			m.cfg.MaxHeight = m.maxHeight
+			m.status = fmt.Sprintf("✔ RESOLUTION CAP SET • %dP", m.maxHeight)
+			m.saveConfig()
+			return m, tea.Batch(cmds...)
+		case "enter", "ctrl+f":
+			// an empty enter opens the selected case's log, when it has one
+			if m.textInput.Value() == "" && msg.String() == "enter" && m.queueIndex < len(m.videoQueue) &&
+				len(m.videoQueue[m.queueIndex].FullLog) > 0 {
+				m.openLog(m.videoQueue[m.queueIndex])
+				break
+			}
+			cmds = append(cmds, m.submit(msg.String() == "ctrl+f", nil)...)
+		case "alt+enter":
+			m.openClipPrompt()
+			return m, tea.Batch(cmds...)
+		case "ctrl+v":
+			// terminal paste can be swallowed while mouse reporting is on
+			text, err := readClipboard()
+			if err != nil {
+				cmds = append(cmds, m.flashStatus("⚠ PASTE FAILED • "+strings.ToUpper(err.Error())))
+				return m, tea.Batch(cmds...)
+			}
+			m.pasteURL(text)
+			return m, tea.Batch(cmds...)
+		case "ctrl+l":
+			cmds = append(cmds, m.openFormatPicker()...)
+		case "ctrl+p":
+			if !downloader.InPlaylist(m.textInput.Value()) {
+				m.status = "⚠ NO PLAYLIST IN THIS LINK • CTRL+P WORKS ON WATCH URLS WITH &LIST="
+				break
+			}
+			m.textInput.SetValue(downloader.PlaylistURL(m.textInput.Value()))
+			cmds = append(cmds, m.submit(false, nil)...)
+		case "x":
+			if !m.hotkeys() {
+				break
+			}
+			if m.queueIndex < len(m.videoQueue) {
+				vd := m.videoQueue[m.queueIndex]
+				if !vd.Done {
+					if vd.Cancel != nil {
+						vd.Cancel()
+					}
+					vd.Cancelled = true
+					vd.Done = true
+					// the downloader's last send has a slot of its own, so
+					// nobody needs to read what is left
+					vd.ProgressCh = nil
+					m.status = fmt.Sprintf("⛔ VARIANT PURGE ABORTED • %s", vd.Name)
+					m.saveQueue(queuePath)
+				}
+			}
+			return m, tea.Batch(cmds...)
+		case "C":
+			if !m.hotkeys() {
+				break
+			}
+			m.clearCompleted()
+			return m, tea.Batch(cmds...)
+		case "ctrl+r":
+			if m.queueIndex < len(m.videoQueue) {
+				cmds = append(cmds, m.retryStalled(m.videoQueue[m.queueIndex])...)
+			}
+			return m, tea.Batch(cmds...)
+		case "u":
+			if !m.hotkeys() {
+				break
+			}
+			m.undoDelete()
+			return m, tea.Batch(cmds...)
+		case "r":
+			if !m.hotkeys() {
+				break
+			}
+			visible := m.visibleHistory()
+			if len(visible) == 0 {
+				break
+			}
+			cmds = append(cmds, m.redownload(visible[m.selectedIndex])...)
+			return m, tea.Batch(cmds...)
+		case "R":
+			if !m.hotkeys() {
+				break
+			}
+			resumed := 0
+			for _, vd := range m.videoQueue {
+				if vd.Interrupted {
+					cmds = append(cmds, m.start(vd)...)
+					resumed++
+				}
+			}
+			if resumed > 0 {
+				m.status = fmt.Sprintf("◉ RESUMING %d INTERRUPTED CASES", resumed)
+			}
+			return m, tea.Batch(cmds...)
+		case "f":
+			if !m.hotkeys() {
+				break
+			}
+			m.toggleFavorite()
+			return m, tea.Batch(cmds...)
+		case "w":
+			if !m.hotkeys() {
+				break
+			}
+			cmds = append(cmds, m.toggleWatch())
+			return m, tea.Batch(cmds...)
+		case "W":
+			if !m.hotkeys() {
+				break
+			}
+			cmds = append(cmds, m.checkWatched(true))
+			return m, tea.Batch(cmds...)
+		case "F":
+			if !m.hotkeys() || m.downloadFormat != "mp3" {
+				break
+			}
+			m.cfg.AudioFormat = nextAudioFormat(m.cfg.AudioFormat)
+			m.status = "✔ AUDIO CODEC SET • " + strings.ToUpper(m.cfg.AudioFormat)
+			if downloader.LosslessAudio(m.cfg.AudioFormat) {
+				m.status += " • LOSSLESS"
+			}
+			m.saveConfig()
+			return m, tea.Batch(cmds...)
+		case "q":
+			if !m.hotkeys() || m.downloadFormat != "mp3" {
+				break
+			}
+			if downloader.LosslessAudio(m.cfg.AudioFormat) {
+				m.status = "⚠ " + strings.ToUpper(m.cfg.AudioFormat) + " IS LOSSLESS • NO BITRATE TO SET"
+				return m, tea.Batch(cmds...)
+			}
+			m.audioQuality = nextAudioQuality(m.audioQuality)
+			m.cfg.AudioQuality = m.audioQuality
+			m.status = "✔ AUDIO QUALITY SET • " + m.audioQuality + "BPS"
+			m.saveConfig()
+			return m, tea.Batch(cmds...)
+		case "a":
+			if !m.hotkeys() || m.downloadFormat != "mp3" {
+				break
+			}
+			m.embedArt = !m.embedArt
+			m.cfg.EmbedArt = m.embedArt
+			if m.embedArt {
+				m.status = "✔ MUSIC MODE ENABLED • ALBUM ART AND TAGS WILL BE EMBEDDED"
 			} else {
-				m.downloadFormat = "mp4"
+				m.status = "✔ MUSIC MODE DISABLED • RAW AUDIO ONLY"
 			}
-		case "enter":
-			url := strings.TrimSpace(m.textInput.Value())
-			if url == "" {
-				m.status = "⚠ INPUT REJECTED • INVALID VARIANT SEQUENCE"
+			m.saveConfig()
+			return m, tea.Batch(cmds...)
+		case "s":
+			if !m.hotkeys() {
 				break
 			}
-
-			vd := &VideoDownload{
-				URL:          url,
-				Name:         "◉ SCANNING TIMELINE...",
-				Percent:      0,
-				Log:          []string{},
-				ProgressCh:   make(chan downloader.ProgressFractionMsg, 50),
-				Done:         false,
-				TitleFetched: false,
+			m.downloadSubs = !m.downloadSubs
+			m.cfg.DownloadSubs = m.downloadSubs
+			if m.downloadSubs {
+				m.status = "✔ SUBTITLE CAPTURE ENABLED • " + strings.ToUpper(strings.Join(m.cfg.SubLangs, ","))
+			} else {
+				m.status = "✔ SUBTITLE CAPTURE DISABLED"
 			}
-
-			m.videoQueue = append(m.videoQueue, vd)
-			m.status = "✔ VARIANT SEQUENCE ACCEPTED • INITIATING CASE ANALYSIS"
-			m.textInput.SetValue("")
-
-			cmds = append(cmds, fetchTitleCmd(vd.URL))
-			cmds = append(cmds, startDownloadCmd(vd, m.downloadFormat))
-		case "up":
-			if m.selectedIndex > 0 {
-				m.selectedIndex--
+			m.saveConfig()
+			return m, tea.Batch(cmds...)
+		case "n":
+			if !m.hotkeys() {
+				break
 			}
-		case "down":
-			if m.selectedIndex < len(m.history)-1 {
-				m.selectedIndex++
+			m.cfg.ConcurrentFragments = nextFragments(m.cfg.ConcurrentFragments)
+			m.status = fmt.Sprintf("✔ CONCURRENT FRAGMENTS SET • %d", m.cfg.ConcurrentFragments)
+			m.saveConfig()
+			return m, tea.Batch(cmds...)
+		case "#":
+			if m.textInput.Value() != "" {
+				break
+			}
+			m.openTagPrompt()
+			return m, tea.Batch(cmds...)
+		case "e":
+			if !m.hotkeys() {
+				break
+			}
+			cmds = append(cmds, m.exportArchive())
+			return m, tea.Batch(cmds...)
+		case "v":
+			if !m.hotkeys() {
+				break
+			}
+			m.verifyArchive()
+			return m, tea.Batch(cmds...)
+		case "t":
+			if !m.hotkeys() {
+				break
+			}
+			m.theme = nextTheme(m.theme)
+			m.cfg.Theme = m.theme.Name
+			m.status = "✔ COLOR SCHEME SET • " + strings.ToUpper(m.theme.Name)
+			m.saveConfig()
+			return m, tea.Batch(cmds...)
+		case "c":
+			if !m.hotkeys() {
+				break
+			}
+			visible := m.visibleHistory()
+			if len(visible) == 0 {
+				break
+			}
+			if err := copyToClipboard(visible[m.selectedIndex].URL); err != nil {
+				cmds = append(cmds, m.flashStatus("⚠ COPY FAILED • "+strings.ToUpper(err.Error())))
+			} else {
+				cmds = append(cmds, m.flashStatus("✔ URL COPIED"))
 			}
+			return m, tea.Batch(cmds...)
+		case "o", "O":
+			if !m.hotkeys() {
+				break
+			}
+			visible := m.visibleHistory()
+			if len(visible) == 0 {
+				break
+			}
+			m.openArchive(visible[m.selectedIndex], msg.String() == "O")
+			return m, tea.Batch(cmds...)
+		case "S":
+			if !m.hotkeys() {
+				break
+			}
+			m.sortMode = m.sortMode.next()
+			m.selectedIndex = 0
+			m.status = "✔ ARCHIVE SORTED BY " + m.sortMode.String()
+			return m, tea.Batch(cmds...)
+		case "p":
+			if !m.hotkeys() {
+				break
+			}
+			if m.paused {
+				cmds = append(cmds, m.resumeQueue()...)
+			} else {
+				m.pauseQueue()
+			}
+			return m, tea.Batch(cmds...)
+		case "E":
+			if !m.hotkeys() {
+				break
+			}
+			m.sortQueueByETA = !m.sortQueueByETA
+			if m.sortQueueByETA {
+				m.status = "✔ ACTIVE CASES ORDERED BY ETA"
+			} else {
+				m.status = "✔ ACTIVE CASES IN QUEUE ORDER"
+			}
+			return m, tea.Batch(cmds...)
+		case "d":
+			if !m.hotkeys() {
+				break
+			}
+			visible := m.visibleHistory()
+			if len(visible) == 0 {
+				break
+			}
+			info := visible[m.selectedIndex]
+			m.prompt = &prompt{
+				question: "PRUNE CASE " + truncateString(strings.ToUpper(info.Title), 40) + "? Y = RECORD ONLY • F = RECORD + FILES • ANY OTHER KEY CANCELS",
+				actions: map[string]func(m *model) tea.Cmd{
+					"y": func(m *model) tea.Cmd { return m.deleteCase(info, false) },
+					"f": func(m *model) tea.Cmd { return m.deleteCase(info, true) },
+				},
+			}
+			return m, tea.Batch(cmds...)
+		case "P":
+			if !m.hotkeys() {
+				break
+			}
+			max := m.cfg.MaxHistory
+			if max <= 0 {
+				m.status = "⚠ NO RETENTION LIMIT • SET MAX_HISTORY IN CONFIG TO PRUNE"
+				break
+			}
+			if len(m.history) <= max {
+				m.status = fmt.Sprintf("✔ ARCHIVE WITHIN LIMIT • %d/%d CASES", len(m.history), max)
+				break
+			}
+			m.prompt = &prompt{
+				question: fmt.Sprintf("PRUNE ARCHIVE TO THE NEWEST %d CASES? MEDIA FILES ARE KEPT • Y = CONFIRM • ANY OTHER KEY CANCELS", max),
+				actions: map[string]func(m *model) tea.Cmd{
+					"y": func(m *model) tea.Cmd { return m.pruneArchive(max) },
+				},
+			}
+			return m, tea.Batch(cmds...)
+		case "shift+up":
+			m.moveQueueSelection(-1)
+			m.previewPending = true
+		case "shift+down":
+			m.moveQueueSelection(1)
+			m.previewPending = true
+		case "alt+up":
+			m.moveQueued(-1)
+		case "alt+down":
+			m.moveQueued(1)
+		case "g":
+			if !m.hotkeys() {
+				break
+			}
+			cmds = append(cmds, m.startPending()...)
+			return m, tea.Batch(cmds...)
+		case "tab":
+			m.setFocus(m.focus + 1)
+			return m, tea.Batch(cmds...)
+		case "shift+tab":
+			m.setFocus(m.focus - 1)
+			return m, tea.Batch(cmds...)
+		case "up":
+			m.moveCursor(-1)
+		case "down":
+			m.moveCursor(1)
+		}
+
+	case clearStatusMsg:
+		if m.status == msg.status {
+			m.status = readyStatus
 		}
 
-	case setFormatMsg:
-		m.downloadFormat = msg.format
-case tickMsg:
+	case tickMsg:
+		cmds = append(cmds, m.launchQueued()...)
 
 		for _, vd := range m.videoQueue {
-			if vd.Done {
+			if vd.Done || vd.Interrupted || vd.Queued {
 				continue
 			}
-			select {
-			case progressMsg, ok := <-vd.ProgressCh:
-				if !ok {
-					vd.Done = true
-					m.status = fmt.Sprintf("✔ ARCHIVE COMPLETE • %s", vd.Name)
+			if cmd := m.drainProgress(vd); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			m.checkStall(vd, time.Now())
+		}
+		m.refreshLog()
+		if m.scanning() {
+			// driven by our own tick rather than the spinner's, so it stops with the scans
+			m.spinner, _ = m.spinner.Update(m.spinner.Tick())
+		}
+		cmds = append(cmds, tickCmd(m.tickInterval()))
+	}
 
-					// reload history so new file appears in list
-					m.history = loadHistory("downloads.json")
-					if m.selectedIndex >= len(m.history) {
-						m.selectedIndex = len(m.history) - 1
-					}
-					break
-				}
+	m.textInput, _ = m.textInput.Update(msg)
+	return m, tea.Batch(cmds...)
+}
 
-				if progressMsg.Fraction >= 0 {
-					vd.Percent = progressMsg.Fraction
-				}
+// drainProgress applies every progress message currently buffered for vd,
+// returning a command to run when the case finishes
+func (m *model) drainProgress(vd *VideoDownload) tea.Cmd {
+	for {
+		select {
+		case progressMsg, ok := <-vd.ProgressCh:
+			if !ok {
+				// closed right after the Done message, nothing more will arrive
+				vd.ProgressCh = nil
+				return nil
+			}
 
-				if progressMsg.Line != "" {
-					vd.Log = append(vd.Log, progressMsg.Line)
-					if len(vd.Log) > 5 {
-						vd.Log = vd.Log[1:]
-					}
-				}
+			if progressMsg.Fraction >= 0 {
+				vd.Percent = progressMsg.Fraction
+			}
 
-				if progressMsg.Fraction > 0 && progressMsg.Fraction < 1 && vd.TitleFetched {
-					m.status = fmt.Sprintf("◉ ARCHIVING VARIANT: %s [%.1f%%]", vd.Name, progressMsg.Fraction*100)
-				}
+			if progressMsg.Speed != "" {
+				vd.Speed = progressMsg.Speed
+			}
+			if progressMsg.ETA != "" {
+				vd.ETA = progressMsg.ETA
+				vd.ETASeconds = etaSeconds(vd.ETA)
+			}
+			if progressMsg.Total > 0 {
+				vd.TotalBytes = progressMsg.Total
+			}
+
+			if progressMsg.Line != "" {
+				vd.FullLog = append(vd.FullLog, progressMsg.Line)
+				vd.Log = lastLines(append(vd.Log, progressMsg.Line), m.cfg.LogLines)
+			}
 
-			default:
+			if progressMsg.Fraction > 0 && progressMsg.Fraction < 1 && vd.TitleFetched {
+				m.status = fmt.Sprintf("◉ ARCHIVING VARIANT: %s [%.1f%%]", vd.Name, progressMsg.Fraction*100)
 			}
+
+			if progressMsg.Done {
+				// Done is always the last message, the close that follows
+				// needs no reader
+				vd.ProgressCh = nil
+				return m.finish(vd, progressMsg.Err)
+			}
+
+		default:
+			return nil
 		}
-		cmds = append(cmds, tickCmd())
 	}
+}
 
-	m.textInput, _ = m.textInput.Update(msg)
-	return m, tea.Batch(cmds...)
+// finish records how a case's download ended, returning a command to run
+// for it (a desktop notification on success)
+func (m *model) finish(vd *VideoDownload, err error) tea.Cmd {
+	vd.Done = true
+	defer m.saveQueue(queuePath)
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		vd.Cancelled = true
+		return nil
+	case err != nil:
+		vd.Failed = true
+		vd.Err = err.Error()
+		m.status = fmt.Sprintf("✖ ARCHIVAL FAILED • %s", vd.Name)
+		var dlErr *downloader.DownloadError
+		if errors.As(err, &dlErr) && dlErr.Reason != "" {
+			vd.Err = dlErr.Reason + " • " + dlErr.Message
+			m.status = fmt.Sprintf("✖ ARCHIVAL FAILED • %s • %s", dlErr.Reason, vd.Name)
+		}
+		if downloader.IsAuthError(vd.Err) {
+			m.status = "⚠ AUTHENTICATION REQUIRED • SET YEET_COOKIES_BROWSER (E.G. FIREFOX) AND RETRY"
+		}
+		return nil
+	}
+
+	vd.Percent = 1
+	m.status = fmt.Sprintf("✔ ARCHIVE COMPLETE • %s", vd.Name)
+
+	// reload history so new file appears in list
+	m.reloadHistory()
+	m.clampSelection()
+
+	if m.quiet(time.Now()) {
+		return nil
+	}
+	var cmds []tea.Cmd
+	if m.cfg.BellOnComplete {
+		cmds = append(cmds, bellCmd())
+	}
+	if m.cfg.Notify {
+		cmds = append(cmds, notifyCmd("Yeet-Tube • Archive complete", vd.Name))
+	}
+	return tea.Batch(cmds...)
+}
+
+// failureReason condenses an error for the status line: the label of a
+// recognised yt-dlp error, otherwise the start of its message
+func failureReason(err error) string {
+	var dlErr *downloader.DownloadError
+	if errors.As(err, &dlErr) {
+		if dlErr.Reason != "" {
+			return dlErr.Reason
+		}
+		return strings.ToUpper(truncateRunes(dlErr.Message, 60))
+	}
+	return strings.ToUpper(truncateRunes(err.Error(), 60))
+}
+
+// openArchive plays an archived file, or reveals it in the file manager
+func (m *model) openArchive(info downloader.VideoInfo, reveal bool) {
+	path := info.FilePath
+	if path == "" {
+		m.status = "⚠ NO FILE PATH RECORDED FOR THIS CASE"
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		m.status = "⚠ ARCHIVED FILE NOT FOUND • " + path
+		return
+	}
+
+	if reveal {
+		if err := openutil.RevealInFolder(path); err != nil {
+			m.status = "⚠ COULD NOT OPEN FOLDER • " + strings.ToUpper(err.Error())
+			return
+		}
+		m.status = "✔ REVEALING ARCHIVE IN FILE MANAGER"
+		return
+	}
+
+	if err := openutil.Open(path); err != nil {
+		m.status = "⚠ COULD NOT OPEN FILE • " + strings.ToUpper(err.Error())
+		return
+	}
+	m.status = "✔ PLAYING ARCHIVE"
+}
+
+// redownload queues an archived case again with the settings it was
+// archived with, asking first when its file is still on disk
+func (m *model) redownload(info downloader.VideoInfo) []tea.Cmd {
+	format := m.downloadFormat
+	if info.Format != "" {
+		format = info.Format
+	} else if strings.EqualFold(filepath.Ext(info.FilePath), ".mp3") || info.EmbeddedArt {
+		format = "mp3"
+	} else if info.FilePath != "" {
+		format = "mp4"
+	}
+	if format != "mp4" && m.ffmpegVersion == "" {
+		m.status = "⚠ " + strings.ToUpper(format) + " REQUIRES FFMPEG • INSTALL IT TO ENABLE"
+		return nil
+	}
+	override := func(opts *downloader.Options) {
+		opts.Format = format
+		if info.AudioFormat != "" {
+			opts.AudioFormat = info.AudioFormat
+		}
+		if format == "mp3" {
+			opts.EmbedArt = info.EmbeddedArt
+		}
+		opts.ClipStart, opts.ClipEnd = info.ClipStart, info.ClipEnd
+		opts.UniqueName = info.UniqueName // keep clear of the case it collided with
+		opts.Overwrite = true
+	}
+
+	requeue := func(m *model) tea.Cmd {
+		delete(m.updates, recordKey(info))
+		m.status = "◉ CASE REQUEUED FOR ARCHIVAL • " + truncateString(strings.ToUpper(info.Title), 40)
+		return tea.Batch(m.enqueue(info.URL, override)...)
+	}
+	if path := downloader.MediaPath(info); path != "" {
+		m.prompt = &prompt{
+			question: "OVERWRITE " + truncateString(path, 50) + "? Y = RE-DOWNLOAD • ANY OTHER KEY CANCELS",
+			actions:  map[string]func(m *model) tea.Cmd{"y": requeue},
+		}
+		return nil
+	}
+	return []tea.Cmd{requeue(m)}
+}
+
+// readyStatus is the idle status line
+const readyStatus = "SYSTEM ONLINE • READY FOR VARIANT INGEST"
+
+// flashStatus shows a status that reverts to idle after a moment,
+// unless something else has replaced it in the meantime
+func (m *model) flashStatus(status string) tea.Cmd {
+	m.status = status
+	return tea.Tick(2*time.Second, func(time.Time) tea.Msg {
+		return clearStatusMsg{status: status}
+	})
+}
+
+// formatName labels a format for the footer
+func formatName(format string) string {
+	switch format {
+	case "mkv":
+		return "MKV (REMUX)"
+	case "mp3":
+		return "AUDIO" // the codec is picked separately with "F"
+	}
+	return strings.ToUpper(format)
+}
+
+// formatFlash is how long the footer highlights a just-toggled format
+const formatFlash = 1500 * time.Millisecond
+
+// setFormat switches the format new cases are enqueued with. Cases already
+// queued keep the format they were enqueued with.
+func (m *model) setFormat(format string) tea.Cmd {
+	m.downloadFormat = format
+	m.cfg.Format = format
+	m.formatToggled = time.Now()
+	m.status = "✔ OUTPUT FORMAT SET • " + formatName(format)
+	m.saveConfig()
+	return m.flashStatus(m.status)
+}
+
+// saveConfig persists a settings hotkey's change, flagging the status line
+// it just set when the file can't be written
+func (m *model) saveConfig() {
+	if err := m.cfg.Save(config.DefaultPath()); err != nil {
+		m.status += " • ⚠ CONFIG NOT SAVED"
+	}
+}
+
+// deleteCase removes an archived case from history, and optionally its media file
+func (m *model) deleteCase(info downloader.VideoInfo, withFile bool) tea.Cmd {
+	removed, err := downloader.DeleteVideoInfo(m.cfg.HistoryPath, info.URL)
+	if err != nil {
+		m.status = "⚠ PRUNE FAILED • " + strings.ToUpper(err.Error())
+		return nil
+	}
+	m.undo = &deletion{records: removed}
+
+	m.status = "✔ CASE PRUNED FROM ARCHIVE • U TO UNDO"
+	if withFile {
+		if err := downloader.DeleteMediaFile(info); err != nil {
+			m.status = "⚠ RECORD PRUNED BUT FILE REMAINS • " + strings.ToUpper(err.Error())
+		} else {
+			m.undo.fileDeleted = true
+			m.status = "✔ CASE AND FILES PRUNED FROM ARCHIVE • U RESTORES THE RECORD"
+		}
+	}
+
+	m.reloadHistory()
+	m.clampSelection()
+	return nil
+}
+
+// deletion is the most recent deleteCase, kept for a single level of undo
+type deletion struct {
+	records     []downloader.VideoInfo
+	fileDeleted bool // the media file is gone for good, only the records come back
+}
+
+// undoDelete puts the records of the last deleted case back in the archive
+func (m *model) undoDelete() {
+	if m.undo == nil {
+		m.status = "⚠ NOTHING TO UNDO"
+		return
+	}
+	if err := downloader.RestoreVideoInfo(m.cfg.HistoryPath, m.undo.records); err != nil {
+		m.status = "⚠ UNDO FAILED • " + strings.ToUpper(err.Error())
+		return
+	}
+
+	m.status = "✔ CASE RESTORED TO ARCHIVE"
+	if m.undo.fileDeleted {
+		m.status += " • ITS FILE WAS DELETED, R TO RE-DOWNLOAD"
+	}
+	m.undo = nil
+	m.reloadHistory()
+	m.clampSelection()
+}
+
+// pruneArchive trims history to the newest max records without touching media
+func (m *model) pruneArchive(max int) tea.Cmd {
+	removed, err := downloader.PruneHistory(m.cfg.HistoryPath, max)
+	if err != nil {
+		m.status = "⚠ PRUNE FAILED • " + strings.ToUpper(err.Error())
+		return nil
+	}
+
+	m.status = fmt.Sprintf("✔ %d OLDEST CASES PRUNED FROM ARCHIVE", removed)
+	m.reloadHistory()
+	m.clampSelection()
+	return nil
+}
+
+// exportArchive writes the whole archive as CSV next to the history file
+func (m *model) exportArchive() tea.Cmd {
+	path := filepath.Join(filepath.Dir(m.cfg.HistoryPath), "history.csv")
+	f, err := os.Create(path)
+	if err != nil {
+		m.status = "⚠ EXPORT FAILED • " + strings.ToUpper(err.Error())
+		return nil
+	}
+	defer f.Close()
+
+	if err := downloader.ExportCSV(m.history, f); err != nil {
+		m.status = "⚠ EXPORT FAILED • " + strings.ToUpper(err.Error())
+		return nil
+	}
+	m.status = fmt.Sprintf("✔ %d CASES EXPORTED • %s", len(m.history), path)
+	return nil
+}
+
+// submit validates the URL in the input box and queues it.
+// force bypasses the duplicate check for intentional re-downloads;
+// override, when set, adjusts the case's options (exact format, clip range).
+func (m *model) submit(force bool, override func(*downloader.Options)) []tea.Cmd {
+	url := strings.TrimSpace(m.textInput.Value())
+	if url == "" {
+		m.status = "⚠ INPUT REJECTED • INVALID VARIANT SEQUENCE"
+		return nil
+	}
+	if !downloader.IsSupportedURL(url) {
+		m.status = "⚠ INPUT REJECTED • NOT A VALID HTTP(S) URL"
+		return nil
+	}
+	if reason := m.duplicateReason(url); reason != "" && !force {
+		m.status = reason + " • CTRL+F TO FORCE"
+		return nil
+	}
+
+	m.textInput.SetValue("")
+	fromPlaylist := downloader.InPlaylist(url)
+	url = downloader.CanonicalizeURL(url)
+
+	if downloader.IsPlaylist(url) {
+		if items := m.cfg.PlaylistRange(); items != "" {
+			return []tea.Cmd{m.expandPlaylist(url, force, items)}
+		}
+		m.openPlaylistPrompt(url, force)
+		return nil
+	}
+
+	if m.cfg.DryRun {
+		return m.enqueuePending(url, override)
+	}
+
+	cmds := m.enqueue(url, override)
+	m.saveQueue(queuePath)
+	m.status = "✔ VARIANT SEQUENCE ACCEPTED • INITIATING CASE ANALYSIS"
+	if fromPlaylist {
+		m.status = "✔ SINGLE VARIANT ACCEPTED FROM A PLAYLIST LINK • CTRL+P INSTEAD OF ENTER QUEUES THE WHOLE PLAYLIST"
+	}
+	return cmds
+}
+
+// duplicateReason explains why url shouldn't be queued again, or returns ""
+func (m model) duplicateReason(url string) string {
+	key := downloader.NormalizeURL(url)
+	for _, vd := range m.videoQueue {
+		if !vd.Done && downloader.NormalizeURL(vd.URL) == key {
+			return "⚠ VARIANT ALREADY IN QUEUE"
+		}
+	}
+	for _, info := range m.history {
+		if downloader.NormalizeURL(info.URL) == key {
+			return "⚠ VARIANT ALREADY ARCHIVED"
+		}
+	}
+	return ""
+}
+
+// enqueue adds a new case to the queue and returns the commands that start it
+func (m *model) enqueue(url string, override func(*downloader.Options)) []tea.Cmd {
+	return m.start(m.addCase(url, override))
+}
+
+// addCase appends a case built from the current settings without starting it
+func (m *model) addCase(url string, override func(*downloader.Options)) *VideoDownload {
+	vd := &VideoDownload{
+		URL:     url,
+		Name:    "◉ SCANNING TIMELINE...",
+		Percent: 0,
+		Options: downloader.Options{
+			Format:       m.downloadFormat,
+			MaxHeight:    m.maxHeight,
+			AudioFormat:  m.cfg.AudioFormat,
+			AudioQuality: m.audioQuality,
+			EmbedArt:     m.embedArt,
+			DownloadSubs: m.downloadSubs,
+			SubLangs:     m.cfg.SubLangs,
+
+			WriteThumbnail: m.cfg.Thumbnails,
+			WriteMetadata:  m.cfg.WriteMetadataFiles,
+			HistoryPath:    m.cfg.HistoryPath,
+			MaxHistory:     m.cfg.MaxHistory,
+
+			CookiesFromBrowser: m.cfg.CookiesFromBrowser,
+			NoFFmpeg:           m.ffmpegVersion == "",
+			OutputTemplate:     m.cfg.OutputTemplate,
+			OutputDir:          m.cfg.OutputDir,
+			TitleTimeout:       m.cfg.TitleTimeout(),
+			RateLimit:          m.cfg.RateLimit,
+			Proxy:              m.cfg.Proxy,
+			SplitChapters:      m.cfg.SplitChapters,
+			ExtraArgs:          m.cfg.ExtraArgs,
+
+			ConcurrentFragments: m.cfg.ConcurrentFragments,
+		},
+		Log:          []string{},
+		Done:         false,
+		TitleFetched: false,
+	}
+
+	if override != nil {
+		override(&vd.Options)
+	}
+
+	m.videoQueue = append(m.videoQueue, vd)
+	return vd
+}
+
+// start queues (or requeues) a case; it launches once a download slot is free
+func (m *model) start(vd *VideoDownload) []tea.Cmd {
+	vd.Interrupted = false
+	vd.Queued = true
+
+	if !vd.TitleFetched {
+		vd.ScanStarted = time.Now()
+		return []tea.Cmd{fetchTitleCmd(vd.URL, vd.Options)}
+	}
+	return nil
+}
+
+// launchQueued starts queued cases in queue order while the pool has free slots
+func (m *model) launchQueued() []tea.Cmd {
+	if m.paused {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, vd := range m.videoQueue {
+		// wait for the title so live streams are caught before yt-dlp hangs on them
+		if !vd.Queued || vd.Done || !vd.TitleFetched {
+			continue
+		}
+		if !m.pool.TryAcquire() {
+			break
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		vd.Cancel = cancel
+		vd.ProgressCh = make(chan downloader.ProgressFractionMsg, 50)
+		vd.Queued = false
+		vd.Options.Pool = m.pool
+		vd.Options.Process = &downloader.Process{}
+		cmds = append(cmds, startDownloadCmd(ctx, vd))
+		m.status = "◉ CASE ARCHIVAL STARTED • OUTPUT: " + downloader.OutputTemplate(vd.Options)
+	}
+	return cmds
 }
 
 // View renders the TUI
 func (m model) View() string {
-	leftWidth := int(float64(m.windowWidth) * 0.35)
+	if m.depErr != nil {
+		return m.depsView()
+	}
+	if m.logCase != nil {
+		return m.logViewerView()
+	}
+	if m.picker != nil {
+		return m.formatPickerView()
+	}
+	if m.showHelp {
+		return m.helpView()
+	}
+
+	if m.windowWidth < minWindowWidth || m.windowHeight < minWindowHeight {
+		return m.tooSmallView()
+	}
+
+	leftWidth := int(float64(m.windowWidth) * m.cfg.LeftPaneRatio)
 	rightWidth := m.windowWidth - leftWidth - 8
-	topHeight := m.windowHeight - 15
+	topHeight := max(m.windowHeight-15, 6)
+	previewHeight := int(float64(topHeight) * m.cfg.PreviewRatio)
+	timelineHeight := int(float64(topHeight) * (1 - m.cfg.PreviewRatio))
 	bottomLeft := int(float64(m.windowWidth) * 0.85)
 
 	if leftWidth < 20 {
@@ -210,15 +1188,15 @@ func (m model) View() string {
 	// Styles
 	headerStyle := lipgloss.NewStyle().
 		Bold(true).
-		Background(lipgloss.Color("#F9BE5E")).
-		Foreground(lipgloss.Color("#1A1A1A")).
+		Background(color(m.theme.Accent)).
+		Foreground(color(m.theme.OnAccent)).
 		Padding(0, 1).
 		Width(m.windowWidth).
 		Align(lipgloss.Center)
 
 	queueBoxStyle := lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("#F9BE5E")).
+		BorderForeground(m.paneBorder(focusQueue, focusHistory)).
 		Padding(1).
 		Width(leftWidth).
 		Height(topHeight).
@@ -226,109 +1204,239 @@ func (m model) View() string {
 
 	previewBoxStyle := lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("#F9BE5E")).
+		BorderForeground(color(m.theme.Border)).
 		Padding(1).
 		Width(rightWidth).
-		Height(topHeight / 2)
+		Height(previewHeight)
 
 	timelineBoxStyle := lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("#F9BE5E")).
+		BorderForeground(color(m.theme.Border)).
 		Padding(1).
 		Width(rightWidth).
-		Height(topHeight / 2)
+		Height(timelineHeight)
 
 	inputBoxStyle := lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("#F9BE5E")).
+		BorderForeground(m.paneBorder(focusInput)).
 		Padding(1).
 		Width(bottomLeft).
 		MarginLeft(2)
 
 	hexBoxStyle := lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("#F9BE5E")).
-		Foreground(lipgloss.Color("#FFcc99")).
-		Width(m.windowWidth - bottomLeft - 9).
-		Height(m.windowHeight - topHeight - 8).
+		BorderForeground(color(m.theme.Border)).
+		Foreground(color(m.theme.Text)).
+		Width(max(m.windowWidth-bottomLeft-9, 1)).
+		Height(max(m.windowHeight-topHeight-8, 1)).
 		MarginLeft(1)
 
 	statusStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#F9BE5E")).
+		Foreground(color(m.theme.Accent)).
 		MarginLeft(2).
 		Bold(true)
 
 	// Header
-	header := headerStyle.Render("TIME VARIANCE AUTHORITY - YEET-TUBE ARCHIVAL CONSOLE v0.2.0")
+	ffmpegBadge := "FFMPEG " + m.ffmpegVersion
+	if m.ffmpegVersion == "" {
+		ffmpegBadge = "⚠ NO FFMPEG"
+	}
+	// the style pads one cell on each side
+	header := headerStyle.Render(headerText(m.windowWidth-2, ffmpegBadge, loadBadge(m.videoQueue)))
 
 	// Queue/history box
 	queueTitle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#F9BE5E")).
-		Render("ARCHIVE HISTORY & ACTIVE CASES")
+		Foreground(color(m.theme.Accent)).
+		Render("ARCHIVE HISTORY & ACTIVE CASES • SORT: " + m.sortMode.String() + queueOrderLabel(m.sortQueueByETA))
 
 	queueContent := queueTitle + "\n\n"
 
 	// Active downloads (progress bars)
-	for _, vd := range m.videoQueue {
+	for _, i := range queueOrder(m.videoQueue, m.sortQueueByETA) {
+		vd := m.videoQueue[i]
 		statusIcon := "…"
-		if vd.Done {
+		if vd.Cancelled {
+			statusIcon = "⛔"
+		} else if vd.Pending {
+			statusIcon = "◌"
+		} else if vd.Paused {
+			statusIcon = "⏸"
+		} else if vd.Failed {
+			statusIcon = "✖"
+		} else if vd.Interrupted {
+			statusIcon = "↺"
+		} else if vd.Queued {
+			statusIcon = "⏳"
+		} else if vd.Done {
 			statusIcon = "☑"
 		} else if vd.Percent > 0 {
 			statusIcon = "▮"
+		} else if !vd.TitleFetched && !vd.ScanStarted.IsZero() {
+			statusIcon = lipgloss.NewStyle().Foreground(color(m.theme.Accent)).Render(m.spinner.View())
 		} else if !vd.TitleFetched {
 			statusIcon = "◉"
 		}
 
-		bar := progress.New(progress.WithScaledGradient("#F9BE5E", "#d98057"))
-		bar.Width = leftWidth - 6
+		details := ""
+		if !vd.Done && !vd.Paused && (vd.Speed != "" || vd.ETA != "") {
+			details = " " + strings.TrimSpace(vd.Speed)
+			if vd.ETA != "" {
+				details += " ETA " + vd.ETA
+			}
+		}
+
+		bar := m.theme.newBar(vd)
+		bar.Width = leftWidth - 6 - lipgloss.Width(details)
+		if bar.Width < 10 {
+			bar.Width = 10
+		}
 
-		queueContent += fmt.Sprintf("[%s] %s\n", statusIcon, vd.Name)
-		if vd.Percent > 0 || vd.Done {
-			queueContent += bar.ViewAs(vd.Percent) + "\n"
+		prefix := "  "
+		if i == m.queueIndex {
+			prefix = m.cursor(focusQueue)
+		}
+
+		name := vd.Name
+		if !vd.TitleFetched && !vd.ScanStarted.IsZero() {
+			remaining := vd.Options.EffectiveTitleTimeout() - time.Since(vd.ScanStarted)
+			if remaining > 0 {
+				name += fmt.Sprintf(" %ds", int(remaining.Seconds())+1)
+			}
+		}
+		if vd.Pending {
+			name += " • DRY RUN [G TO START]"
+		} else if vd.Paused {
+			name += " • ⏸ PAUSED"
+		} else if vd.Stalled && running(vd) {
+			name += " • ⚠ STALLED [CTRL+R TO RETRY]"
+		} else if vd.Queued {
+			name += fmt.Sprintf(" • QUEUED #%d", queuePosition(m.videoQueue, i))
+		}
+
+		queueContent += fmt.Sprintf("%s[%s] %s %s %s\n", prefix, statusIcon, percentLabel(vd), formatBadge(vd.Options, m.theme), name)
+		if (vd.Percent > 0 || vd.Done) && !vd.Cancelled {
+			queueContent += bar.ViewAs(vd.Percent) + details + "\n"
+		}
+		if vd.Failed {
+			queueContent += lipgloss.NewStyle().
+				Foreground(color(m.theme.Error)).
+				Render("    "+truncateString(strings.ToUpper(vd.Err), leftWidth-10)) + "\n"
+		}
+		if i == m.queueIndex && running(vd) {
+			for _, line := range vd.Log {
+				queueContent += lipgloss.NewStyle().
+					Foreground(color(m.theme.Muted)).
+					Render("    "+truncateString(line, leftWidth-10)) + "\n"
+			}
 		}
 	}
 
 	// Completed history
-	if len(m.history) == 0 {
+	visible := m.visibleHistory()
+	if m.filtering || m.filterInput.Value() != "" {
+		queueContent += "\n" + m.filterInput.View() + "\n"
+	}
+	if len(visible) == 0 {
 		queueContent += lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#888888")).
+			Foreground(color(m.theme.Muted)).
 			Italic(true).
 			Render("\nNO ARCHIVED CASES")
 	} else {
 		queueContent += "\n"
-		for i, info := range m.history {
+		for i, info := range visible {
+			if i == 0 && info.IsFavorite {
+				queueContent += lipgloss.NewStyle().Foreground(color(m.theme.Accent)).Render("★ PINNED") + "\n"
+			} else if i > 0 && visible[i-1].IsFavorite && !info.IsFavorite {
+				queueContent += "\n"
+			}
+
 			prefix := "  "
 			if i == m.selectedIndex {
-				prefix = "➤ "
+				prefix = m.cursor(focusHistory)
+			}
+			if info.IsFavorite {
+				prefix += "★ "
+			}
+			flag := ""
+			if problem, ok := m.integrity[recordKey(info)]; ok {
+				flag = " ⚠ " + problem
 			}
-			queueContent += fmt.Sprintf("%s%s\n", prefix, truncateString(strings.ToUpper(info.Title), leftWidth-6))
+			better := ""
+			if m.updates[recordKey(info)] {
+				better = " ⬆ BETTER FORMAT"
+			}
+			// badges only take room the title can spare, the upgrade hint going first
+			room := leftWidth - 4 - lipgloss.Width(prefix)
+			if len([]rune(flag+better)) > room-minTitleWidth {
+				better = ""
+			}
+			if len([]rune(flag)) > room-minTitleWidth {
+				flag = ""
+			}
+			queueContent += prefix + truncateString(strings.ToUpper(info.Title), max(room-len([]rune(flag+better)), 1))
+			if flag != "" {
+				queueContent += lipgloss.NewStyle().Foreground(color(m.theme.Error)).Render(flag)
+			}
+			if better != "" {
+				queueContent += lipgloss.NewStyle().Foreground(color(m.theme.Accent)).Render(better)
+			}
+			queueContent += "\n"
 		}
 	}
 
 	// Preview box
 	previewTitle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#F9BE5E")).
+		Foreground(color(m.theme.Accent)).
 		Render("ARCHIVE PREVIEW")
 
+	previewInfo, hasPreview := m.previewInfo(visible)
+	if m.previewedPending() != nil {
+		previewTitle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(color(m.theme.Accent)).
+			Render("DRY RUN PREVIEW • NOT YET ARCHIVED")
+	}
+
 	previewContent := previewTitle + "\n\n"
-	if len(m.history) > 0 {
-		info := m.history[m.selectedIndex]
+	if hasPreview {
+		info := previewInfo
+		downloaded := "PENDING"
+		if !info.DownloadedAt.IsZero() {
+			downloaded = info.DownloadedAt.Format("2006-01-02 15:04:05")
+		}
 		previewContent += fmt.Sprintf(
-			"TITLE: %s\nURL: %s\nDURATION: %.0fs\nRESOLUTION: %s (%dx%d)\nFPS: %d\nVIDEO BITRATE: %.1f kbps\nAUDIO BITRATE: %.1f kbps\nSIZE: %d MB\nDOWNLOADED: %s",
+			"TITLE: %s\nURL: %s\nSOURCE: %s\nCHANNEL: %s\nDURATION: %.0fs\nRESOLUTION: %s (%dx%d)\nFPS: %d\nVIDEO BITRATE: %.1f kbps\nAUDIO BITRATE: %.1f kbps\nSIZE: %d MB\nFORMAT: %s\nCONTAINER: %s\nSUBTITLES: %s\nALBUM ART: %s\nCLIP: %s\nCHAPTERS: %s\nTAGS: %s\nFILE: %s\nDOWNLOADED: %s",
 			info.Title,
 			info.URL,
+			orUnknown(info.Extractor),
+			orUnknown(info.Channel),
 			info.Duration,
 			info.Resolution, info.Width, info.Height,
 			info.FPS,
 			info.VBR, info.ABR,
 			info.Filesize/1024/1024,
-			info.DownloadedAt.Format("2006-01-02 15:04:05"),
+			orUnknown(strings.ToUpper(info.Format)),
+			orUnknown(strings.ToUpper(info.Container)),
+			yesNo(info.HasSubtitles),
+			yesNo(info.EmbeddedArt),
+			clipLabel(info),
+			chaptersLabel(info),
+			tagsLabel(info.Tags),
+			orUnknown(info.FilePath),
+			downloaded,
 		)
+
+		// Show the thumbnail beside the metadata when there's room for it
+		if thumbWidth := rightWidth - 64; thumbWidth >= 16 {
+			if art := m.thumbnailView(info.ThumbnailPath, thumbWidth, max(previewHeight-6, 1)); art != "" {
+				previewContent = lipgloss.JoinHorizontal(lipgloss.Top, previewContent, "  ", art)
+			}
+		}
 	} else {
 		previewContent += lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#888888")).
+			Foreground(color(m.theme.Muted)).
 			Italic(true).
 			Render("NO ARCHIVES YET")
 	}
@@ -336,26 +1444,87 @@ func (m model) View() string {
 	// Input box
 	inputTitle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("#F9BE5E")).
+		Foreground(color(m.theme.Accent)).
 		Render("NEW CASE ENTRY")
 
 	inputContent := inputTitle + "\n\n" + m.textInput.View()
-	inputContent += "\n\n" + lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#888888")).
-		Render("PRESS ENTER TO CONFIRM • ESC TO EXIT • M TO TOGGLE FORMAT: "+strings.ToUpper(m.downloadFormat))
+	muted := lipgloss.NewStyle().Foreground(color(m.theme.Muted))
+	formatStyle := muted
+	if time.Since(m.formatToggled) < formatFlash {
+		formatStyle = lipgloss.NewStyle().
+			Bold(true).
+			Background(color(m.theme.Accent)).
+			Foreground(color(m.theme.OnAccent))
+	}
+	formatLabel := formatStyle.Render("FORMAT: " + formatName(m.downloadFormat) + " [M]")
+
+	var settings []string
+	if m.downloadFormat == "mp3" {
+		settings = append(settings, "CODEC: "+strings.ToUpper(m.cfg.AudioFormat)+" [SHIFT+F]")
+		if !downloader.LosslessAudio(m.cfg.AudioFormat) {
+			settings = append(settings, "QUALITY: "+m.audioQuality+" [Q]")
+		}
+		if m.cfg.AudioFormat != "wav" {
+			settings = append(settings, "ALBUM ART: "+onOff(m.embedArt)+" [A]")
+		}
+	} else {
+		settings = append(settings, fmt.Sprintf("CAP: %dP [H]", m.maxHeight))
+	}
+	settings = append(settings, "SUBS: "+onOff(m.downloadSubs)+" [S]", fmt.Sprintf("FRAGMENTS: %d [N]", m.cfg.ConcurrentFragments))
+	if m.cfg.SplitChapters && m.ffmpegVersion != "" {
+		settings = append(settings, "CHAPTERS: SPLIT")
+	}
+	if m.cfg.DryRun {
+		settings = append(settings, "DRY RUN: ON")
+	}
+
+	inputContent += "\n\n" +
+		muted.Render("PRESS ENTER TO CONFIRM (EMPTY: VIEW CASE LOG) • TAB FOR HOTKEYS • ? FOR ALL KEYBINDINGS • ESC TO EXIT") + "\n" +
+		formatLabel + muted.Render(" • "+strings.Join(settings, " • "))
 
 	// Hex vanity box
 	hexBoxContent := hexBoxStyle.Render(formattedHexStream(7, 6))
 
-	// top right box
+	// top right box: stats, then sources when there's room, then the timeline
+	panels := []string{statsView(downloader.HistoryStats(m.history), m.theme), "    "}
+	timelineWidth := rightWidth - 36
+	if timelineWidth-sourcesWidth >= minTimelineWidth {
+		panels = append(panels, sourcesView(downloader.SourceBreakdown(m.history), 5, m.theme), "    ")
+		timelineWidth -= sourcesWidth
+	}
+	panels = append(panels, timelineView(m.history, timelineWidth, time.Now(), m.theme))
+
 	topRightContent := lipgloss.JoinVertical(
 		lipgloss.Right,
 		previewBoxStyle.Render(previewContent),
-		timelineBoxStyle.Render("timeline"),
+		timelineBoxStyle.Render(lipgloss.JoinHorizontal(lipgloss.Top, panels...)),
 	)
 
 	// Status
 	statusContent := "\n" + statusStyle.Render("STATUS: "+m.status)
+	if m.cfg.RateLimit != "" {
+		statusContent += statusStyle.Render(" • RATE CAP " + strings.ToUpper(m.cfg.RateLimit) + "/S")
+	}
+	if m.cfg.Proxy != "" {
+		statusContent += statusStyle.Render(" • VIA PROXY " + strings.ToUpper(proxyHost(m.cfg.Proxy)))
+	}
+	if m.prompt != nil {
+		statusContent = "\n" + statusStyle.Render("CONFIRM: "+m.prompt.question)
+	}
+	if m.tagInput != nil {
+		statusContent = "\n" + statusStyle.Render("TAG (ENTER ADDS, OR REMOVES IF ALREADY SET • ESC TO CANCEL): #") + m.tagInput.View()
+	}
+	if m.clipInput != nil {
+		statusContent = "\n" + statusStyle.Render("CLIP RANGE (HH:MM:SS-HH:MM:SS • ENTER TO QUEUE • ESC TO CANCEL): ") + m.clipInput.View()
+	}
+	if m.playlistPrompt != nil {
+		statusContent = "\n" + statusStyle.Render("PLAYLIST ITEMS (E.G. 1-10,15 • ENTER TO RESOLVE • ESC TO CANCEL): ") + m.playlistPrompt.input.View()
+	}
+	if fraction, ok := aggregateProgress(m.videoQueue); ok {
+		overall := progress.New(progress.WithScaledGradient(m.theme.GradientStart, m.theme.GradientEnd))
+		overall.Width = m.windowWidth / 3
+		statusContent += "\n" + statusStyle.Render("OVERALL: ") + overall.ViewAs(fraction)
+	}
 
 	// Layout
 	topRow := lipgloss.JoinHorizontal(
@@ -373,12 +1542,250 @@ func (m model) View() string {
 	return header + "\n\n" + topRow + "\n" + bottomRow + statusContent
 }
 
+// The main console layout needs at least this much room
+const (
+	minWindowWidth  = 80
+	minWindowHeight = 24
+)
+
+// minTitleWidth is the least a history title keeps before its badges are dropped
+const minTitleWidth = 8
+
+// Bounds for the configurable layout ratios; beyond them a pane no longer
+// fits its content at the minimum window size
+const (
+	minLeftPaneRatio = 0.2
+	maxLeftPaneRatio = 0.6
+	minPreviewRatio  = 0.25
+	maxPreviewRatio  = 0.75
+)
+
+// clampRatio limits a layout ratio to [lo, hi]
+func clampRatio(r, lo, hi float64) float64 {
+	return min(max(r, lo), hi)
+}
+
+// tooSmallView replaces the console when the terminal can't fit it
+func (m model) tooSmallView() string {
+	msg := fmt.Sprintf("⚠ TERMINAL TOO SMALL\n\n%dx%d • NEED %dx%d\nRESIZE TO CONTINUE",
+		m.windowWidth, m.windowHeight, minWindowWidth, minWindowHeight)
+	return lipgloss.Place(
+		max(m.windowWidth, 1), max(m.windowHeight, 1),
+		lipgloss.Center, lipgloss.Center,
+		lipgloss.NewStyle().Bold(true).Foreground(color(m.theme.Accent)).Align(lipgloss.Center).Render(msg),
+	)
+}
+
+// formatBadge labels a queue entry with the format it was enqueued with,
+// so mixed mp3/mp4 queues stay readable after the global toggle changes
+func formatBadge(opts downloader.Options, theme Theme) string {
+	label := strings.ToUpper(opts.Format)
+	if label == "" {
+		label = "MP4" // queues saved before formats were recorded
+	}
+	if opts.Format == "mp3" && opts.AudioFormat != "" {
+		label = strings.ToUpper(opts.AudioFormat)
+	}
+	if opts.ClipStart != "" {
+		label += " ✂ " + opts.ClipStart + "-" + opts.ClipEnd
+	}
+	if opts.FormatID != "" {
+		id, _, _ := strings.Cut(opts.FormatID, "+")
+		label += " #" + id
+	} else if opts.Format == "mp3" && !downloader.LosslessAudio(opts.AudioFormat) {
+		label += " " + opts.AudioQuality
+	} else if opts.MaxHeight > 0 {
+		label += fmt.Sprintf(" %dP", opts.MaxHeight)
+	}
+	return lipgloss.NewStyle().
+		Foreground(color(theme.Info)).
+		Render("[" + label + "]")
+}
+
+// aggregateProgress averages progress across unfinished cases, weighting by
+// size when every case has reported one. ok is false when nothing is in flight.
+func aggregateProgress(queue []*VideoDownload) (fraction float64, ok bool) {
+	var sum, weighted float64
+	var totalBytes int64
+	sized := true
+	count := 0
+
+	for _, vd := range queue {
+		if vd.Done || vd.Interrupted || vd.Pending {
+			continue
+		}
+		count++
+		sum += vd.Percent
+		if vd.TotalBytes <= 0 {
+			sized = false
+		}
+		totalBytes += vd.TotalBytes
+		weighted += vd.Percent * float64(vd.TotalBytes)
+	}
+
+	if count == 0 {
+		return 0, false
+	}
+	if sized && totalBytes > 0 {
+		return weighted / float64(totalBytes), true
+	}
+	return sum / float64(count), true
+}
+
+// nextAudioFormat returns the audio codec following current, wrapping around
+func nextAudioFormat(current string) string {
+	i := slices.Index(downloader.AudioFormats, current)
+	return downloader.AudioFormats[(i+1)%len(downloader.AudioFormats)]
+}
+
+// nextAudioQuality returns the bitrate following current, wrapping around
+func nextAudioQuality(current string) string {
+	qualities := downloader.AudioQualities
+	for i, q := range qualities {
+		if q == current {
+			return qualities[(i+1)%len(qualities)]
+		}
+	}
+	return downloader.DefaultAudioQuality
+}
+
+// resolutionCaps are the selectable video height limits, in cycle order
+var resolutionCaps = []int{480, 720, 1080, 1440, 2160}
+
+// nextResolution returns the cap following current, wrapping around
+func nextResolution(current int) int {
+	for i, h := range resolutionCaps {
+		if h == current {
+			return resolutionCaps[(i+1)%len(resolutionCaps)]
+		}
+	}
+	return resolutionCaps[0]
+}
+
+// fragmentSteps are the selectable concurrent fragment counts, in cycle order
+var fragmentSteps = []int{1, 2, 4, 8, 16}
+
+// nextFragments returns the fragment count following current, wrapping around
+func nextFragments(current int) int {
+	for i, n := range fragmentSteps {
+		if n == current {
+			return fragmentSteps[(i+1)%len(fragmentSteps)]
+		}
+	}
+	return fragmentSteps[0]
+}
+
+// percentLabel is the fixed-width progress readout on a queue entry, blank
+// until the case has made progress so names stay aligned either way
+func percentLabel(vd *VideoDownload) string {
+	if vd.Percent <= 0 || vd.Cancelled {
+		return strings.Repeat(" ", len("[100.0%]"))
+	}
+	return fmt.Sprintf("[%5.1f%%]", vd.Percent*100)
+}
+
+// clipLabel notes in the preview whether only a section was archived
+func clipLabel(info downloader.VideoInfo) string {
+	if info.ClipStart == "" {
+		return "FULL"
+	}
+	return info.ClipStart + "-" + info.ClipEnd + " (PARTIAL ARCHIVE)"
+}
+
+// chaptersLabel notes in the preview whether a case was split into chapters
+func chaptersLabel(info downloader.VideoInfo) string {
+	if len(info.ChapterFiles) == 0 {
+		return "NOT SPLIT"
+	}
+	return fmt.Sprintf("SPLIT INTO %d CHAPTERS", len(info.ChapterFiles))
+}
+
+// tagsLabel lists a case's tags for the preview
+func tagsLabel(tags []string) string {
+	if len(tags) == 0 {
+		return "NONE"
+	}
+	return "#" + strings.ToUpper(strings.Join(tags, " #"))
+}
+
+// scanning reports whether any case is still waiting for its title
+func (m model) scanning() bool {
+	for _, vd := range m.videoQueue {
+		if !vd.TitleFetched && !vd.ScanStarted.IsZero() && !vd.Done {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyHost strips the scheme and any credentials from a proxy URL for display
+func proxyHost(proxy string) string {
+	if u, err := url.Parse(proxy); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return proxy
+}
+
+// lastLines keeps the newest n lines, or none when n <= 0
+func lastLines(lines []string, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	if len(lines) > n {
+		return lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// onOff renders a toggle for the input footer
+func onOff(b bool) string {
+	if b {
+		return "ON"
+	}
+	return "OFF"
+}
+
+// yesNo renders a flag for the preview box
+func yesNo(b bool) string {
+	if b {
+		return "YES"
+	}
+	return "NO"
+}
+
+// orUnknown substitutes a placeholder for metadata that was never recorded
+func orUnknown(s string) string {
+	if s == "" {
+		return "UNKNOWN"
+	}
+	return s
+}
+
 // Helper functions
+// humanSize formats a byte count as KB, MB or GB
+func humanSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// truncateString shortens s to at most maxLen runes, ending in "..." when
+// there is room for it
 func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	runes := []rune(s)
+	if len(runes) <= maxLen {
 		return s
 	}
-	return s[:maxLen-3] + "..."
+	if maxLen < 4 {
+		return string(runes[:max(maxLen, 0)])
+	}
+	return string(runes[:maxLen-3]) + "..."
 }
 
 func randomHexString(length int) string {
@@ -390,33 +1797,45 @@ func randomHexString(length int) string {
 	return string(b)
 }
 
-func loadHistory(path string) []downloader.VideoInfo {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return []downloader.VideoInfo{}
+// reloadHistory rereads the archive, warning when the file had to be
+// salvaged rather than quietly showing an empty list
+func (m *model) reloadHistory() {
+	infos, err := downloader.LoadHistory(m.cfg.HistoryPath)
+	m.history = infos
+	if m.history == nil {
+		m.history = []downloader.VideoInfo{}
+	}
+
+	var corrupt *downloader.CorruptHistoryError
+	if errors.As(err, &corrupt) {
+		m.status = fmt.Sprintf("⚠ ARCHIVE FILE CORRUPTED • %d CASES SALVAGED", corrupt.Salvaged)
+		if corrupt.Backup != "" {
+			m.status += " • ORIGINAL SAVED TO " + corrupt.Backup
+		}
 	}
-	var infos []downloader.VideoInfo
-	json.Unmarshal(data, &infos)
-	return infos
 }
 
+// titleTimeoutGrace lets the downloader's own timeout report first
+const titleTimeoutGrace = time.Second
+
 // fetchTitleCmd starts async title fetching
-func fetchTitleCmd(url string) tea.Cmd {
+func fetchTitleCmd(url string, opts downloader.Options) tea.Cmd {
 	return func() tea.Msg {
 		resultCh := make(chan downloader.TitleFetchedMsg, 1)
 
-		downloader.FetchTitleAsync(url, func(msg downloader.TitleFetchedMsg) {
+		downloader.FetchTitleAsync(url, opts, func(msg downloader.TitleFetchedMsg) {
 			resultCh <- msg
 		})
 
 		select {
 		case result := <-resultCh:
 			return titleFetchedMsg{
-				url:   result.URL,
-				title: result.Title,
-				err:   result.Error,
+				url:        result.URL,
+				title:      result.Title,
+				liveStatus: result.LiveStatus,
+				err:        result.Error,
 			}
-		case <-time.After(10 * time.Second):
+		case <-time.After(opts.EffectiveTitleTimeout() + titleTimeoutGrace):
 			return titleFetchedMsg{
 				url:   url,
 				title: "",
@@ -426,25 +1845,45 @@ func fetchTitleCmd(url string) tea.Cmd {
 	}
 }
 
+// expandPlaylistCmd resolves a playlist URL into its individual videos
+func expandPlaylistCmd(url string, force bool, opts downloader.Options) tea.Cmd {
+	return func() tea.Msg {
+		urls, err := downloader.ExpandPlaylist(url, opts)
+		return playlistExpandedMsg{url: url, urls: urls, err: err, force: force}
+	}
+}
+
 // startDownloadCmd launches the downloader in a goroutine
-func startDownloadCmd(vd *VideoDownload, format string) tea.Cmd {
+func startDownloadCmd(ctx context.Context, vd *VideoDownload) tea.Cmd {
 	return func() tea.Msg {
-		downloader.DownloadStreamWithProgress(vd.URL, format, func(f float64, line string) {
-			select {
-			case vd.ProgressCh <- downloader.ProgressFractionMsg{
-				Fraction: f,
-				Line:     line,
-			}:
-			default:
-			}
-		})
+		downloader.DownloadStreamWithProgress(ctx, vd.URL, vd.Options, vd.ProgressCh)
 		return nil
 	}
 }
 
+// idleTick is the refresh interval when nothing is in flight
+const idleTick = time.Second
+
+// Bounds for cfg.TickRateMS
+const (
+	minTickRateMS = 16
+	maxTickRateMS = 1000
+)
+
+// tickInterval refreshes at cfg.TickRate while any case is queued, running
+// or still being identified, and drops to idleTick otherwise to spare the CPU
+func (m model) tickInterval() time.Duration {
+	for _, vd := range m.videoQueue {
+		if !vd.Done && !vd.Interrupted && !vd.Pending {
+			return m.cfg.TickRate()
+		}
+	}
+	return max(idleTick, m.cfg.TickRate())
+}
+
 // tickCmd schedules the next tick
-func tickCmd() tea.Cmd {
-	return tea.Tick(time.Millisecond*100, func(time.Time) tea.Msg { // 10fps
+func tickCmd(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(time.Time) tea.Msg {
 		return tickMsg{}
 	})
 }
@@ -0,0 +1,125 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"yeet-tube/downloader"
+)
+
+func TestDrainProgressReleasesChannelOnDone(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+
+	ch := make(chan downloader.ProgressFractionMsg, 4)
+	ch <- downloader.ProgressFractionMsg{Fraction: 0.5, Line: "[download]  50.0%"}
+	ch <- downloader.ProgressFractionMsg{Fraction: -1, Done: true, Err: context.Canceled}
+	close(ch)
+	vd := &VideoDownload{URL: "https://example.com/v", Name: "V", ProgressCh: ch}
+	m.videoQueue = []*VideoDownload{vd}
+
+	m.drainProgress(vd)
+
+	if !vd.Done || vd.Percent != 0.5 {
+		t.Errorf("Done = %v, Percent = %v after draining, want finished at 0.5", vd.Done, vd.Percent)
+	}
+	if vd.ProgressCh != nil {
+		t.Error("finished case still holds its progress channel")
+	}
+}
+
+// tick runs one tickMsg through Update
+func tick(t *testing.T, m model) model {
+	t.Helper()
+	updated, _ := m.Update(tickMsg{})
+	return updated.(model)
+}
+
+func TestTickAppliesProgressAndFinishes(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+
+	ch := make(chan downloader.ProgressFractionMsg, 8)
+	vd := &VideoDownload{URL: "https://example.com/v", Name: "V", TitleFetched: true, ProgressCh: ch}
+	m.videoQueue = []*VideoDownload{vd}
+
+	ch <- downloader.ProgressFractionMsg{Fraction: 0.42, Line: "[download]  42.0%", Speed: "1.50MiB/s", ETA: "00:10", Total: 1 << 20}
+	m = tick(t, m)
+	if vd.Done {
+		t.Fatal("case finished before its Done message")
+	}
+	if vd.Percent != 0.42 || vd.Speed != "1.50MiB/s" || vd.ETA != "00:10" || vd.TotalBytes != 1<<20 {
+		t.Errorf("progress not applied: Percent %v, Speed %q, ETA %q, TotalBytes %d", vd.Percent, vd.Speed, vd.ETA, vd.TotalBytes)
+	}
+	if len(vd.Log) != 1 {
+		t.Errorf("log = %q, want the progress line", vd.Log)
+	}
+
+	ch <- downloader.ProgressFractionMsg{Fraction: 1, Done: true}
+	close(ch)
+	m = tick(t, m)
+	if !vd.Done || vd.Failed || vd.Percent != 1 {
+		t.Errorf("after Done: Done %v, Failed %v, Percent %v, want a completed case", vd.Done, vd.Failed, vd.Percent)
+	}
+	if !strings.HasPrefix(m.status, "✔ ARCHIVE COMPLETE") {
+		t.Errorf("status = %q, want the completion notice", m.status)
+	}
+}
+
+func TestTickRecordsFailure(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+
+	ch := make(chan downloader.ProgressFractionMsg, 2)
+	ch <- downloader.ProgressFractionMsg{Fraction: 1, Done: true, Err: errors.New("boom")}
+	close(ch)
+	vd := &VideoDownload{URL: "https://example.com/v", Name: "V", ProgressCh: ch}
+	m.videoQueue = []*VideoDownload{vd}
+
+	tick(t, m)
+	if !vd.Done || !vd.Failed || vd.Err != "boom" {
+		t.Errorf("Done %v, Failed %v, Err %q, want a failed case", vd.Done, vd.Failed, vd.Err)
+	}
+}
+
+// setFormatMsg is gone; the "m" hotkey calls setFormat directly
+func TestFormatHotkeyCyclesFormat(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+	m.setFocus(focusHistory)
+
+	for _, want := range []string{"mkv", "mp3", "mp4"} {
+		updated, _ := m.Update(key("m"))
+		m = updated.(model)
+		if m.downloadFormat != want || m.cfg.Format != want {
+			t.Fatalf("format = %q (config %q), want %q", m.downloadFormat, m.cfg.Format, want)
+		}
+	}
+}
+
+func TestFormatHotkeyNeedsFFmpeg(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+	m.ffmpegVersion = ""
+	m.setFocus(focusHistory)
+
+	updated, _ := m.Update(key("m"))
+	m = updated.(model)
+	if m.downloadFormat != "mp4" {
+		t.Errorf("format = %q without ffmpeg, want mp4", m.downloadFormat)
+	}
+}
+
+func TestRedownloadKeepsRecordedAlbumArt(t *testing.T) {
+	for _, embedded := range []bool{true, false} {
+		m := testModel(t, 120, 40, nil)
+		m.embedArt = !embedded
+		info := downloader.VideoInfo{URL: "https://example.com/v", Title: "V", Format: "mp3", AudioFormat: "m4a", EmbeddedArt: embedded}
+
+		m.redownload(info)
+		if len(m.videoQueue) != 1 {
+			t.Fatalf("%d cases queued, want 1", len(m.videoQueue))
+		}
+		opts := m.videoQueue[0].Options
+		if opts.Format != "mp3" || opts.AudioFormat != "m4a" || opts.EmbedArt != embedded {
+			t.Errorf("requeued as %s/%s with art %v, want mp3/m4a with art %v", opts.Format, opts.AudioFormat, opts.EmbedArt, embedded)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// bellCmd rings the terminal bell. It goes to stderr so it can't land in
+// the middle of a frame the renderer is writing to stdout.
+func bellCmd() tea.Cmd {
+	return func() tea.Msg {
+		fmt.Fprint(os.Stderr, "\a")
+		return nil
+	}
+}
+
+// parseQuietHours reads a "HH:MM-HH:MM" range as minutes after midnight.
+// The range may wrap past midnight, e.g. "22:00-07:00".
+func parseQuietHours(spec string) (start, end int, err error) {
+	from, to, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("want HH:MM-HH:MM")
+	}
+	if start, err = minuteOfDay(from); err != nil {
+		return 0, 0, err
+	}
+	if end, err = minuteOfDay(to); err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// minuteOfDay converts an HH:MM clock time to minutes after midnight
+func minuteOfDay(clock string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(clock))
+	if err != nil {
+		return 0, fmt.Errorf("want HH:MM, got %q", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// quiet reports whether now falls in cfg.QuietHours, when completion
+// bells and notifications are held back
+func (m model) quiet(now time.Time) bool {
+	if m.cfg.QuietHours == "" {
+		return false
+	}
+	start, end, err := parseQuietHours(m.cfg.QuietHours)
+	if err != nil {
+		return false
+	}
+	minute := now.Hour()*60 + now.Minute()
+	if start <= end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
@@ -0,0 +1,38 @@
+package tui
+
+import "fmt"
+
+// clearCompleted drops finished, failed and cancelled cases from the queue;
+// their records are already in the archive
+func (m *model) clearCompleted() {
+	var selected *VideoDownload
+	if m.queueIndex < len(m.videoQueue) {
+		selected = m.videoQueue[m.queueIndex]
+	}
+
+	kept := m.videoQueue[:0]
+	cleared := 0
+	for _, vd := range m.videoQueue {
+		if vd.Done {
+			cleared++
+			continue
+		}
+		kept = append(kept, vd)
+	}
+	clear(m.videoQueue[len(kept):])
+	m.videoQueue = kept
+
+	m.queueIndex = 0
+	for i, vd := range m.videoQueue {
+		if vd == selected {
+			m.queueIndex = i
+		}
+	}
+
+	if cleared == 0 {
+		m.status = "⚠ NO COMPLETED CASES TO CLEAR"
+		return
+	}
+	m.status = fmt.Sprintf("✔ %d COMPLETED CASES CLEARED FROM THE QUEUE", cleared)
+	m.saveQueue(queuePath)
+}
@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"yeet-tube/downloader"
+)
+
+func key(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestClearCompletedDropsFinishedAndAborted(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+
+	running := &VideoDownload{URL: "https://example.com/a", Name: "A", ProgressCh: make(chan downloader.ProgressFractionMsg, 1)}
+	aborted := &VideoDownload{URL: "https://example.com/b", Name: "B", ProgressCh: make(chan downloader.ProgressFractionMsg, 1)}
+	finished := &VideoDownload{URL: "https://example.com/c", Name: "C", Done: true}
+	m.videoQueue = []*VideoDownload{running, aborted, finished}
+
+	m.setFocus(focusQueue)
+	m.queueIndex = 1
+	updated, _ := m.Update(key("x"))
+	m = updated.(model)
+	if !aborted.Cancelled || aborted.ProgressCh != nil {
+		t.Fatalf("abort left Cancelled = %v, ProgressCh = %v", aborted.Cancelled, aborted.ProgressCh)
+	}
+
+	updated, _ = m.Update(key("C"))
+	m = updated.(model)
+	if len(m.videoQueue) != 1 || m.videoQueue[0] != running {
+		names := make([]string, len(m.videoQueue))
+		for i, vd := range m.videoQueue {
+			names[i] = vd.Name
+		}
+		t.Errorf("queue after clearing = %v, want only the running case", names)
+	}
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"yeet-tube/downloader"
+)
+
+// openClipPrompt asks for a time range before queueing the URL in the input box
+func (m *model) openClipPrompt() {
+	url := strings.TrimSpace(m.textInput.Value())
+	if !downloader.IsSupportedURL(url) {
+		m.status = "⚠ INPUT REJECTED • NOT A VALID HTTP(S) URL"
+		return
+	}
+	if downloader.IsPlaylist(url) {
+		m.status = "⚠ CLIPS WORK ON SINGLE VARIANTS, NOT PLAYLISTS"
+		return
+	}
+
+	ci := textinput.New()
+	ci.Placeholder = "00:01:00-00:01:30"
+	ci.CharLimit = 17
+	ci.Width = 20
+	ci.Focus()
+	m.clipInput = &ci
+	m.textInput.Blur()
+}
+
+// closeClipPrompt hands the keyboard back to the focused pane
+func (m *model) closeClipPrompt() {
+	m.clipInput = nil
+	m.restoreFocus()
+}
+
+// updateClip handles key presses while the clip range is being entered
+func (m model) updateClip(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.requestQuit()
+	case "esc":
+		m.closeClipPrompt()
+		m.status = readyStatus
+		return m, nil
+	case "enter":
+		start, end, _ := strings.Cut(strings.TrimSpace(m.clipInput.Value()), "-")
+		start, end = strings.TrimSpace(start), strings.TrimSpace(end)
+		if err := downloader.ValidateClip(start, end); err != nil {
+			m.status = "⚠ INVALID CLIP RANGE • " + strings.ToUpper(err.Error())
+			return m, nil
+		}
+		m.closeClipPrompt()
+		// a clip is a different archive from the full video, so skip the duplicate check
+		cmds := m.submit(true, func(opts *downloader.Options) {
+			opts.ClipStart, opts.ClipEnd = start, end
+		})
+		return m, tea.Batch(cmds...)
+	}
+
+	var cmd tea.Cmd
+	*m.clipInput, cmd = m.clipInput.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"yeet-tube/downloader"
+)
+
+// errNoClipboard is returned when no clipboard tool is available (e.g. headless sessions)
+var errNoClipboard = errors.New("no clipboard available")
+
+// copyToClipboard places text on the system clipboard
+func copyToClipboard(text string) error {
+	if clipboard.Unsupported {
+		return errNoClipboard
+	}
+	return clipboard.WriteAll(text)
+}
+
+// readClipboard returns the text on the system clipboard
+func readClipboard() (string, error) {
+	if clipboard.Unsupported {
+		return "", errNoClipboard
+	}
+	return clipboard.ReadAll()
+}
+
+type clipboardURLMsg struct {
+	url string
+}
+
+// clipboardURLCmd looks for a supported URL on the clipboard, for
+// cfg.AutoFillFromClipboard. Anything else is ignored silently.
+func clipboardURLCmd() tea.Cmd {
+	return func() tea.Msg {
+		text, err := readClipboard()
+		text = strings.TrimSpace(text)
+		if err != nil || len(text) > maxURLLength || !downloader.IsSupportedURL(text) {
+			return nil
+		}
+		return clipboardURLMsg{url: text}
+	}
+}
+
+// autoFill pre-fills the URL input unless the user has started typing
+func (m *model) autoFill(url string) {
+	if m.textInput.Value() != "" {
+		return
+	}
+	m.textInput.SetValue(url)
+	m.textInput.CursorEnd()
+	// keep startup warnings such as recovered cases visible
+	if m.status == readyStatus {
+		m.status = "◉ URL PRE-FILLED FROM CLIPBOARD • PRESS ENTER TO QUEUE"
+	}
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"yeet-tube/downloader"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// installHints tells the user how to get each external tool
+var installHints = map[string]string{
+	"yt-dlp": "https://github.com/yt-dlp/yt-dlp#installation  (e.g. brew install yt-dlp / pipx install yt-dlp)",
+	"ffmpeg": "https://ffmpeg.org/download.html  (e.g. brew install ffmpeg / apt install ffmpeg)",
+}
+
+// checkDependencies returns the error that should block the console (only a
+// missing yt-dlp does) and the detected ffmpeg version, "" when ffmpeg is absent
+func checkDependencies() (blocking error, ffmpegVersion string) {
+	err := downloader.CheckDependencies()
+
+	var missing *downloader.MissingDependenciesError
+	if errors.As(err, &missing) && !missing.Has("yt-dlp") {
+		err = nil
+	}
+
+	if version, verr := downloader.FFmpegVersion(); verr == nil {
+		ffmpegVersion = version
+	}
+	return err, ffmpegVersion
+}
+
+// updateDeps handles key presses while dependencies are missing
+func (m model) updateDeps(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		return m, tea.Quit
+	case "enter":
+		m.depErr, m.ffmpegVersion = checkDependencies()
+	}
+	return m, nil
+}
+
+// depsView renders the blocking screen shown until dependencies are installed
+func (m model) depsView() string {
+	accent := lipgloss.NewStyle().Bold(true).Foreground(color(m.theme.Accent))
+	muted := lipgloss.NewStyle().Foreground(color(m.theme.Muted))
+
+	lines := []string{
+		accent.Render("⚠ ARCHIVAL CONSOLE OFFLINE • REQUIRED TOOLS NOT FOUND"),
+		"",
+	}
+
+	var missing *downloader.MissingDependenciesError
+	if errors.As(m.depErr, &missing) {
+		for _, tool := range missing.Missing {
+			lines = append(lines, "  ✖ "+strings.ToUpper(tool), muted.Render("    "+installHints[tool]))
+		}
+	} else {
+		lines = append(lines, "  "+m.depErr.Error())
+	}
+
+	lines = append(lines, "", muted.Render("INSTALL THE TOOLS ABOVE, THEN PRESS ENTER TO RECHECK • ESC TO EXIT"))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(color(m.theme.Border)).
+		Padding(1, 2).
+		Render(strings.Join(lines, "\n"))
+
+	return lipgloss.Place(m.windowWidth, m.windowHeight, lipgloss.Center, lipgloss.Center, box)
+}
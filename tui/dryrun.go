@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"yeet-tube/downloader"
+)
+
+type infoFetchedMsg struct {
+	url  string
+	info downloader.VideoInfo
+	err  error
+}
+
+func fetchInfoCmd(url string, opts downloader.Options) tea.Cmd {
+	return func() tea.Msg {
+		info, err := downloader.FetchVideoInfo(url, opts)
+		return infoFetchedMsg{url: url, info: info, err: err}
+	}
+}
+
+// enqueuePending adds a dry-run case: its metadata is collected for the
+// preview, but nothing is downloaded until it is started with "g"
+func (m *model) enqueuePending(url string, override func(*downloader.Options)) []tea.Cmd {
+	vd := m.addCase(url, override)
+	vd.Pending = true
+
+	m.queueIndex = len(m.videoQueue) - 1
+	m.previewPending = true
+	m.status = "◉ DRY RUN • COLLECTING CASE METADATA"
+	return []tea.Cmd{fetchInfoCmd(url, vd.Options)}
+}
+
+// infoFetched attaches dry-run metadata to its pending case
+func (m *model) infoFetched(msg infoFetchedMsg) {
+	for _, vd := range m.videoQueue {
+		if vd.URL != msg.url || !vd.Pending || vd.Info != nil {
+			continue
+		}
+		vd.TitleFetched = true
+		if msg.err != nil {
+			vd.Name = truncateString(strings.ToUpper(msg.url), 28)
+			m.status = "⚠ DRY RUN METADATA FAILED • " + strings.ToUpper(msg.err.Error())
+			return
+		}
+		vd.Info = &msg.info
+		vd.Name = truncateString(strings.ToUpper(msg.info.Title), 28)
+		m.status = "✔ DRY RUN COMPLETE • " + vd.Name + " • PRESS G TO ARCHIVE"
+		m.checkLive(vd, msg.info.LiveStatus)
+		return
+	}
+}
+
+// startPending turns the selected dry-run case into a real download, asking
+// first when its estimated size is above cfg.LargeDownloadMB
+func (m *model) startPending() []tea.Cmd {
+	if m.queueIndex >= len(m.videoQueue) || !m.videoQueue[m.queueIndex].Pending {
+		m.status = "⚠ SELECT A DRY-RUN CASE WITH SHIFT+↑/↓ FIRST"
+		return nil
+	}
+
+	vd := m.videoQueue[m.queueIndex]
+	limit := int64(m.cfg.LargeDownloadMB) << 20
+	if limit > 0 && vd.Info != nil && vd.Info.Filesize > limit {
+		m.prompt = &prompt{
+			question: "THIS VARIANT IS " + humanSize(vd.Info.Filesize) + " — PROCEED? Y = ARCHIVE • ANY OTHER KEY CANCELS",
+			actions: map[string]func(m *model) tea.Cmd{
+				"y": func(m *model) tea.Cmd { return tea.Batch(m.promotePending(vd)...) },
+			},
+		}
+		return nil
+	}
+	return m.promotePending(vd)
+}
+
+// promotePending queues a dry-run case for download
+func (m *model) promotePending(vd *VideoDownload) []tea.Cmd {
+	vd.Pending = false
+	cmds := m.start(vd)
+	m.saveQueue(queuePath)
+	m.status = "✔ DRY RUN PROMOTED • " + vd.Name + " QUEUED FOR ARCHIVAL"
+	return cmds
+}
+
+// previewedPending returns the dry-run case the preview should show, if any
+func (m model) previewedPending() *VideoDownload {
+	if !m.previewPending || m.queueIndex >= len(m.videoQueue) {
+		return nil
+	}
+	if vd := m.videoQueue[m.queueIndex]; vd.Pending && vd.Info != nil {
+		return vd
+	}
+	return nil
+}
+
+// previewInfo picks the metadata for the preview box: the selected dry-run
+// case when one is focused, otherwise the selected archive entry
+func (m model) previewInfo(visible []downloader.VideoInfo) (downloader.VideoInfo, bool) {
+	if vd := m.previewedPending(); vd != nil {
+		return *vd.Info, true
+	}
+	if len(visible) > 0 {
+		return visible[m.selectedIndex], true
+	}
+	return downloader.VideoInfo{}, false
+}
@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"strings"
+
+	"yeet-tube/downloader"
+)
+
+// toggleFavorite pins or unpins the selected archive case, keeping the
+// cursor on it as it moves in or out of the pinned section
+func (m *model) toggleFavorite() {
+	visible := m.visibleHistory()
+	if len(visible) == 0 {
+		return
+	}
+	info := visible[m.selectedIndex]
+
+	pinned, err := downloader.ToggleFavorite(m.cfg.HistoryPath, info.URL)
+	if err != nil {
+		m.status = "⚠ PIN NOT SAVED • " + strings.ToUpper(err.Error())
+		return
+	}
+
+	m.status = "✔ CASE UNPINNED"
+	if pinned {
+		m.status = "★ CASE PINNED TO THE TOP"
+	}
+	m.reloadHistory()
+	for i, v := range m.visibleHistory() {
+		if recordKey(v) == recordKey(info) {
+			m.selectedIndex = i
+			break
+		}
+	}
+	m.clampSelection()
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"yeet-tube/downloader"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// filterHistory returns the entries whose title or URL contain query
+// (case-insensitive). A query starting with # matches tags instead.
+func filterHistory(infos []downloader.VideoInfo, query string) []downloader.VideoInfo {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return infos
+	}
+
+	matches := []downloader.VideoInfo{}
+	if tag, ok := strings.CutPrefix(query, "#"); ok {
+		tag = downloader.NormalizeTag(tag)
+		for _, info := range infos {
+			if tag == "" || slices.Contains(info.Tags, tag) {
+				matches = append(matches, info)
+			}
+		}
+		return matches
+	}
+
+	for _, info := range infos {
+		if strings.Contains(strings.ToLower(info.Title), query) ||
+			strings.Contains(strings.ToLower(info.URL), query) {
+			matches = append(matches, info)
+		}
+	}
+	return matches
+}
+
+// visibleHistory is the history list after the active filter and sort are applied
+func (m model) visibleHistory() []downloader.VideoInfo {
+	return pinFavorites(sortHistory(filterHistory(m.history, m.filterInput.Value()), m.sortMode))
+}
+
+// clampSelection keeps selectedIndex inside the visible history
+func (m *model) clampSelection() {
+	if n := len(m.visibleHistory()); m.selectedIndex >= n {
+		m.selectedIndex = n - 1
+	}
+	if m.selectedIndex < 0 {
+		m.selectedIndex = 0
+	}
+}
+
+// startFilter switches keyboard input over to the history filter box
+func (m *model) startFilter() {
+	m.filtering = true
+	m.textInput.Blur()
+	m.filterInput.Focus()
+}
+
+// clearFilter drops the filter and restores the full history list
+func (m *model) clearFilter() {
+	m.filtering = false
+	m.filterInput.SetValue("")
+	m.filterInput.Blur()
+	m.restoreFocus()
+	m.clampSelection()
+	m.status = "✔ FILTER CLEARED • FULL ARCHIVE RESTORED"
+}
+
+// updateFilter handles key presses while the filter box has focus
+func (m model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.requestQuit()
+	case "esc":
+		m.clearFilter()
+		return m, nil
+	case "enter":
+		m.filtering = false
+		m.filterInput.Blur()
+		m.restoreFocus()
+		return m, nil
+	case "up":
+		if m.selectedIndex > 0 {
+			m.selectedIndex--
+		}
+		return m, nil
+	case "down":
+		if m.selectedIndex < len(m.visibleHistory())-1 {
+			m.selectedIndex++
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	m.clampSelection()
+	m.status = fmt.Sprintf("%d/%d CASES MATCH", len(m.visibleHistory()), len(m.history))
+	return m, cmd
+}
@@ -0,0 +1,65 @@
+package tui
+
+import "github.com/charmbracelet/lipgloss"
+
+// focus is the pane that up/down and the cursor highlight belong to
+type focus int
+
+const (
+	focusInput   focus = iota // typing a URL; up/down still move through the archive
+	focusQueue                // active cases
+	focusHistory              // archived cases
+)
+
+// setFocus moves keyboard focus to f, cycled by tab and shift+tab
+func (m *model) setFocus(f focus) {
+	m.focus = (f + 3) % 3
+	m.previewPending = m.focus == focusQueue
+	m.restoreFocus()
+}
+
+// restoreFocus gives the URL input its cursor back if it is the focused
+// pane, after a prompt or the filter box borrowed the keyboard
+func (m *model) restoreFocus() {
+	if m.focus == focusInput {
+		m.textInput.Focus()
+	} else {
+		m.textInput.Blur()
+	}
+}
+
+// hotkeys reports whether letter keys act as shortcuts. They only do
+// while a list has focus: in the URL box every letter is part of a URL.
+func (m model) hotkeys() bool {
+	return m.focus != focusInput
+}
+
+// moveCursor moves the selection of the focused list by delta rows
+func (m *model) moveCursor(delta int) {
+	if m.focus == focusQueue {
+		m.moveQueueSelection(delta)
+		m.previewPending = true
+		return
+	}
+	m.previewPending = false
+	m.selectedIndex = max(min(m.selectedIndex+delta, len(m.visibleHistory())-1), 0)
+}
+
+// paneBorder colors the border of a box holding the given panes, drawing
+// attention to the one with focus
+func (m model) paneBorder(panes ...focus) lipgloss.Color {
+	for _, p := range panes {
+		if p == m.focus {
+			return color(m.theme.Accent)
+		}
+	}
+	return color(m.theme.Border)
+}
+
+// cursor marks the selected row of a list, dimmed unless the list has focus
+func (m model) cursor(pane focus) string {
+	if pane == m.focus || (pane == focusHistory && m.focus == focusInput) {
+		return lipgloss.NewStyle().Bold(true).Foreground(color(m.theme.Accent)).Render("➤ ")
+	}
+	return lipgloss.NewStyle().Foreground(color(m.theme.Muted)).Render("➤ ")
+}
@@ -0,0 +1,179 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"yeet-tube/downloader"
+)
+
+// formatPicker lists the formats available for the URL in the input box
+type formatPicker struct {
+	url     string
+	formats []downloader.Format // best first
+	cursor  int
+	loading bool
+}
+
+type formatsListedMsg struct {
+	url     string
+	formats []downloader.Format
+	err     error
+}
+
+func listFormatsCmd(url string, opts downloader.Options) tea.Cmd {
+	return func() tea.Msg {
+		formats, err := downloader.ListFormats(url, opts)
+		return formatsListedMsg{url: url, formats: formats, err: err}
+	}
+}
+
+// openFormatPicker inspects the URL in the input box, reusing formats
+// already listed this session
+func (m *model) openFormatPicker() []tea.Cmd {
+	url := strings.TrimSpace(m.textInput.Value())
+	if !downloader.IsSupportedURL(url) {
+		m.status = "⚠ INPUT REJECTED • NOT A VALID HTTP(S) URL"
+		return nil
+	}
+	if downloader.IsPlaylist(url) {
+		m.status = "⚠ FORMAT SELECTION WORKS ON SINGLE VARIANTS, NOT PLAYLISTS"
+		return nil
+	}
+
+	url = downloader.CanonicalizeURL(url)
+	if formats, ok := m.formatCache[url]; ok {
+		m.picker = &formatPicker{url: url, formats: formats}
+		return nil
+	}
+
+	m.picker = &formatPicker{url: url, loading: true}
+	m.status = "◉ INSPECTING VARIANT FORMATS..."
+	opts := downloader.Options{CookiesFromBrowser: m.cfg.CookiesFromBrowser, Proxy: m.cfg.Proxy}
+	return []tea.Cmd{listFormatsCmd(url, opts)}
+}
+
+// formatsListed stores a ListFormats result and fills the picker waiting for it
+func (m *model) formatsListed(msg formatsListedMsg) {
+	waiting := m.picker != nil && m.picker.url == msg.url
+	if msg.err != nil {
+		m.status = "⚠ FORMAT INSPECTION FAILED • " + strings.ToUpper(msg.err.Error())
+		if waiting {
+			m.picker = nil
+		}
+		return
+	}
+
+	formats := slices.Clone(msg.formats)
+	slices.Reverse(formats) // yt-dlp lists worst to best
+	m.formatCache[msg.url] = formats
+
+	if waiting {
+		m.picker.formats = formats
+		m.picker.loading = false
+		m.status = fmt.Sprintf("✔ %d FORMATS FOUND • PICK ONE TO QUEUE", len(formats))
+	}
+}
+
+// pickerFormats returns the formats that make sense for the current output
+// format: mp3 extraction needs a stream with audio
+func (m model) pickerFormats() []downloader.Format {
+	if m.downloadFormat != "mp3" {
+		return m.picker.formats
+	}
+	var audio []downloader.Format
+	for _, f := range m.picker.formats {
+		if f.HasAudio() {
+			audio = append(audio, f)
+		}
+	}
+	return audio
+}
+
+// updatePicker handles key presses while the format picker is open
+func (m model) updatePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	formats := m.pickerFormats()
+
+	switch msg.String() {
+	case "ctrl+c":
+		return m.requestQuit()
+	case "esc", "q":
+		m.picker = nil
+		m.status = readyStatus
+	case "up", "k":
+		if m.picker.cursor > 0 {
+			m.picker.cursor--
+		}
+	case "down", "j":
+		if m.picker.cursor < len(formats)-1 {
+			m.picker.cursor++
+		}
+	case "enter":
+		if m.picker.loading || len(formats) == 0 {
+			break
+		}
+		selector := formats[m.picker.cursor].Selector(m.ffmpegVersion == "")
+		m.picker = nil
+		cmds := m.submit(false, func(opts *downloader.Options) { opts.FormatID = selector })
+		return m, tea.Batch(cmds...)
+	}
+	return m, nil
+}
+
+// formatPickerView renders the full-screen format list
+func (m model) formatPickerView() string {
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Background(color(m.theme.Accent)).
+		Foreground(color(m.theme.OnAccent)).
+		Padding(0, 1).
+		Width(m.windowWidth).
+		Render("FORMAT SELECTION • " + m.picker.url)
+
+	rows := max(m.windowHeight-8, 1)
+	var body string
+	formats := m.pickerFormats()
+	switch {
+	case m.picker.loading:
+		body = "◉ INSPECTING VARIANT FORMATS..."
+	case len(formats) == 0:
+		body = "NO USABLE FORMATS FOUND"
+	default:
+		body = fmt.Sprintf("  %-10s %-5s %-11s %-4s %-14s %-12s %10s  %s",
+			"ID", "EXT", "RESOLUTION", "FPS", "VCODEC", "ACODEC", "SIZE", "NOTE")
+		offset := max(m.picker.cursor-rows+1, 0)
+		for i := offset; i < len(formats) && i < offset+rows; i++ {
+			f := formats[i]
+			prefix := "  "
+			if i == m.picker.cursor {
+				prefix = "➤ "
+			}
+			size := "?"
+			if f.Filesize > 0 {
+				size = humanSize(f.Filesize)
+			}
+			body += "\n" + fmt.Sprintf("%s%-10s %-5s %-11s %-4.0f %-14s %-12s %10s  %s",
+				prefix,
+				truncateString(f.ID, 10), f.Ext,
+				truncateString(f.Resolution, 11), f.FPS,
+				truncateString(f.VCodec, 14), truncateString(f.ACodec, 12),
+				size, f.Note)
+		}
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(color(m.theme.Border)).
+		Width(max(m.windowWidth-2, 1)).
+		Height(rows + 1).
+		Render(body)
+
+	footer := lipgloss.NewStyle().
+		Foreground(color(m.theme.Muted)).
+		Render("↑/↓ SELECT • ENTER TO QUEUE WITH THIS FORMAT • ESC TO RETURN")
+
+	return title + "\n" + box + "\n" + footer
+}
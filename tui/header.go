@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+	"yeet-tube/downloader"
+)
+
+// loadBadge summarizes the queue for the header
+func loadBadge(queue []*VideoDownload) string {
+	active, queued, done, failed := 0, 0, 0, 0
+	for _, vd := range queue {
+		switch {
+		case vd.Failed:
+			failed++
+		case vd.Done && !vd.Cancelled:
+			done++
+		case vd.Queued && !vd.Done:
+			queued++
+		case running(vd):
+			active++
+		}
+	}
+	badge := fmt.Sprintf("ACTIVE: %d • QUEUED: %d • DONE: %d", active, queued, done)
+	if failed > 0 {
+		badge += fmt.Sprintf(" • FAILED: %d", failed)
+	}
+	if rate := throughput(queue); rate > 0 {
+		badge += fmt.Sprintf(" • %.1f MB/S", rate/(1<<20))
+	}
+	return badge
+}
+
+// throughput sums the last reported speed of every download that is
+// moving, in bytes per second
+func throughput(queue []*VideoDownload) float64 {
+	total := 0.0
+	for _, vd := range queue {
+		if running(vd) && !vd.Paused && !vd.Stalled {
+			total += downloader.SpeedBytes(vd.Speed)
+		}
+	}
+	return total
+}
+
+// headerText picks the longest header that fits in width, shortening the
+// title first and dropping the ffmpeg badge next so the load badge stays
+func headerText(width int, ffmpegBadge, load string) string {
+	candidates := []string{
+		"TIME VARIANCE AUTHORITY - YEET-TUBE ARCHIVAL CONSOLE v0.2.0 • " + ffmpegBadge + " • " + load,
+		"YEET-TUBE ARCHIVAL CONSOLE v0.2.0 • " + ffmpegBadge + " • " + load,
+		"YEET-TUBE • " + load,
+	}
+	for _, text := range candidates {
+		if lipgloss.Width(text) <= width {
+			return text
+		}
+	}
+	return truncateRunes(load, width)
+}
+
+// truncateRunes shortens s to at most width cells, marking the cut with "…"
+func truncateRunes(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string(runes[:max(width, 0)])
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"yeet-tube/config"
+	"yeet-tube/downloader"
+)
+
+// missingRunner fails every command, as if no external tool were installed
+type missingRunner struct{}
+
+func (missingRunner) Run(ctx context.Context, name string, args ...string) (io.Reader, io.Reader, func() error, error) {
+	return nil, nil, nil, errors.New(name + " not installed")
+}
+
+// testModel builds a console of the given size in a scratch directory, with
+// yt-dlp and ffmpeg treated as present but never actually run
+func testModel(t *testing.T, width, height int, configure func(*config.Config)) model {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("XDG_CONFIG_HOME", dir) // settings hotkeys save the config
+	t.Setenv("HOME", dir)
+
+	runner := downloader.Runner
+	downloader.Runner = missingRunner{}
+	t.Cleanup(func() { downloader.Runner = runner })
+
+	cfg := config.Default()
+	cfg.HistoryPath = "downloads.json"
+	if configure != nil {
+		configure(cfg)
+	}
+	m := InitialModel(cfg)
+	m.depErr, m.ffmpegVersion = nil, "6.1.1"
+
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	return updated.(model)
+}
+
+// recordingRunner remembers the arguments of every command and succeeds
+// without output
+type recordingRunner struct {
+	mu    sync.Mutex
+	calls [][]string
+}
+
+func (r *recordingRunner) Run(ctx context.Context, name string, args ...string) (io.Reader, io.Reader, func() error, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, args)
+	return strings.NewReader(""), strings.NewReader(""), func() error { return nil }, nil
+}
+
+// downloads returns the arguments of every yt-dlp download started so far
+func (r *recordingRunner) downloads() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var calls [][]string
+	for _, args := range r.calls {
+		if slices.Contains(args, "--newline") {
+			calls = append(calls, args)
+		}
+	}
+	return calls
+}
+
+// record swaps in a recordingRunner until the test ends
+func record(t *testing.T) *recordingRunner {
+	t.Helper()
+	r := &recordingRunner{}
+	old := downloader.Runner
+	downloader.Runner = r
+	t.Cleanup(func() { downloader.Runner = old })
+	return r
+}
+
+// launch starts every queued case and waits for their downloads to finish
+func launch(t *testing.T, m *model) {
+	t.Helper()
+	for _, cmd := range m.launchQueued() {
+		cmd()
+	}
+	for _, vd := range m.videoQueue {
+		if vd.ProgressCh == nil {
+			continue
+		}
+		timeout := time.After(5 * time.Second)
+		for open := true; open; {
+			select {
+			case _, open = <-vd.ProgressCh:
+			case <-timeout:
+				t.Fatalf("download of %s never finished", vd.URL)
+			}
+		}
+	}
+}
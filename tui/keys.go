@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyBinding documents one key of the main console
+type keyBinding struct {
+	keys   string // as shown to the user
+	action string
+}
+
+// keyGroup is a titled section of the help overlay
+type keyGroup struct {
+	title    string
+	bindings []keyBinding
+}
+
+// keymap lists every binding of the main console. Add new keys here so the
+// help overlay stays in sync with Update.
+var keymap = []keyGroup{
+	{"CASE ENTRY", []keyBinding{
+		{"ENTER", "queue the URL in the input box (empty: open the selected case log)"},
+		{"CTRL+F", "queue even if the URL is already queued or archived"},
+		{"CTRL+V", "paste a URL from the clipboard"},
+		{"CTRL+L", "inspect formats and queue with an exact one"},
+		{"CTRL+P", "queue the whole playlist a watch URL with &list= belongs to"},
+		{"ALT+ENTER", "queue only a clip (asks for HH:MM:SS-HH:MM:SS)"},
+	}},
+	{"ACTIVE CASES", []keyBinding{
+		{"SHIFT+↑/↓", "select a queued case (↑/↓ while the queue has focus)"},
+		{"ALT+↑/↓", "move the selected queued case earlier or later in line"},
+		{"X", "abort the selected case"},
+		{"CTRL+R", "restart the selected stalled case from its partial file"},
+		{"P", "pause or resume the whole queue"},
+		{"SHIFT+C", "clear finished, failed and aborted cases from the queue"},
+		{"G", "start the selected dry-run case"},
+		{"SHIFT+R", "resume interrupted cases"},
+		{"SHIFT+E", "toggle ordering by ETA"},
+	}},
+	{"ARCHIVE", []keyBinding{
+		{"↑/↓", "select an archived case (unless the queue has focus)"},
+		{"/", "filter the archive (start with # to filter by tag)"},
+		{"#", "add or remove a tag on the selected case"},
+		{"F", "pin or unpin the selected case at the top of the list"},
+		{"SHIFT+S", "cycle sort order"},
+		{"D", "delete the selected case, optionally with its media and sidecar files"},
+		{"U", "undo the last deletion"},
+		{"R", "re-download the selected case with its original format"},
+		{"SHIFT+P", "prune the archive to max_history"},
+		{"E", "export the archive to history.csv"},
+		{"V", "verify archived files exist and match their recorded size"},
+		{"W", "watch the selected case for a better format (checked at startup)"},
+		{"SHIFT+W", "check watched cases for a better format now"},
+		{"C", "copy the selected URL"},
+		{"O", "play the selected file"},
+		{"SHIFT+O", "reveal the selected file in the file manager"},
+	}},
+	{"SETTINGS", []keyBinding{
+		{"M", "cycle mp4, mkv (remux, no re-encoding) and audio"},
+		{"SHIFT+F", "cycle the audio codec: mp3, m4a, opus, flac, wav (audio)"},
+		{"H", "cycle the resolution cap (mp4)"},
+		{"Q", "cycle the audio bitrate (lossy audio)"},
+		{"A", "toggle album art and tags (audio)"},
+		{"S", "toggle subtitles"},
+		{"N", "cycle concurrent fragment downloads"},
+		{"T", "cycle the color scheme"},
+	}},
+	{"GENERAL", []keyBinding{
+		{"TAB", "move focus between the input, the queue and the archive (SHIFT+TAB goes back)"},
+		{"A-Z", "letter keys only work while the queue or the archive has focus"},
+		{"?", "show or hide this help"},
+		{"ESC", "clear the filter, or save the queue and exit"},
+		{"CTRL+C", "save the queue and exit, confirming first if downloads are active"},
+	}},
+}
+
+// updateHelp handles key presses while the help overlay is open
+func (m model) updateHelp(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.requestQuit()
+	case "esc", "?", "q":
+		m.showHelp = false
+	}
+	return m, nil
+}
+
+// helpView renders the keymap centered over the console
+func (m model) helpView() string {
+	keyStyle := lipgloss.NewStyle().Bold(true).Foreground(color(m.theme.Accent))
+	groupStyle := lipgloss.NewStyle().Bold(true).Underline(true).Foreground(color(m.theme.Accent))
+
+	var b strings.Builder
+	b.WriteString(keyStyle.Render("KEYBINDINGS"))
+	for _, group := range keymap {
+		b.WriteString("\n\n" + groupStyle.Render(group.title))
+		for _, kb := range group.bindings {
+			b.WriteString("\n" + keyStyle.Render(fmt.Sprintf("%-10s", kb.keys)) + " " + strings.ToUpper(kb.action))
+		}
+	}
+	b.WriteString("\n\n" + lipgloss.NewStyle().Foreground(color(m.theme.Muted)).Render("ESC OR ? TO CLOSE"))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(color(m.theme.Border)).
+		Padding(1, 2).
+		Render(b.String())
+
+	return lipgloss.Place(max(m.windowWidth, 1), max(m.windowHeight, 1), lipgloss.Center, lipgloss.Center, box)
+}
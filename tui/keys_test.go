@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"yeet-tube/config"
+)
+
+func TestSettingsHotkeysSaveConfig(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+	m.setFocus(focusHistory)
+
+	updated, _ := m.Update(key("h"))
+	m = updated.(model)
+	saved, err := config.Load(config.DefaultPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.MaxHeight != m.maxHeight || saved.MaxHeight == config.Default().MaxHeight {
+		t.Errorf("saved cap = %d, want the new %d", saved.MaxHeight, m.maxHeight)
+	}
+	if strings.Contains(m.status, "CONFIG NOT SAVED") {
+		t.Errorf("status = %q after a successful save", m.status)
+	}
+}
+
+func TestSettingsHotkeysFlagFailedSaves(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+	// a file where the config directory should be
+	blocker := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", blocker)
+	m.setFocus(focusHistory)
+
+	for _, k := range []string{"h", "s", "t", "m"} {
+		m.status = readyStatus
+		updated, _ := m.Update(key(k))
+		m = updated.(model)
+		if !strings.HasSuffix(m.status, " • ⚠ CONFIG NOT SAVED") {
+			t.Errorf("%s: status = %q, want the failed save flagged", k, m.status)
+		}
+	}
+}
+
+func TestTypingAURLTriggersNoHotkeys(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+	m.videoQueue = []*VideoDownload{{URL: "https://example.com/v", Name: "V", Queued: true, TitleFetched: true}}
+	before := *m.cfg
+
+	const url = "https://youtu.be/abc?si=mwpqFtxRe&t=42"
+	for _, r := range url {
+		updated, _ := m.Update(key(string(r)))
+		m = updated.(model)
+	}
+	if m.textInput.Value() != url {
+		t.Errorf("input = %q, want %q", m.textInput.Value(), url)
+	}
+	if !reflect.DeepEqual(*m.cfg, before) {
+		t.Errorf("typing a URL changed the config:\n%+v\nwas\n%+v", *m.cfg, before)
+	}
+	if _, err := os.Stat(config.DefaultPath()); err == nil {
+		t.Error("typing a URL saved the config")
+	}
+	if m.paused || m.videoQueue[0].Done || m.showHelp {
+		t.Error("typing a URL acted on the console")
+	}
+}
+
+func TestAudioKeysOutsideMp3Mode(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+	m.setFocus(focusHistory)
+
+	updated, _ := m.Update(key("a"))
+	m = updated.(model)
+	if m.embedArt {
+		t.Error("a toggled album art in mp4 mode")
+	}
+}
+
+func TestThemeKeyNeedsAListFocused(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+	theme := m.theme.Name
+
+	updated, _ := m.Update(key("t"))
+	m = updated.(model)
+	if m.theme.Name != theme || m.textInput.Value() != "t" {
+		t.Errorf("theme %q, input %q: t in the URL box changed the theme", m.theme.Name, m.textInput.Value())
+	}
+	if _, err := os.Stat(config.DefaultPath()); err == nil {
+		t.Error("typing t saved the config")
+	}
+
+	m.textInput.SetValue("")
+	m.setFocus(focusHistory)
+	updated, _ = m.Update(key("t"))
+	m = updated.(model)
+	if m.theme.Name == theme {
+		t.Error("t with the archive focused didn't cycle the theme")
+	}
+}
+
+func TestPauseKeyNeedsAListFocused(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+	m.videoQueue = []*VideoDownload{{URL: "https://example.com/v", Name: "V", Queued: true, TitleFetched: true}}
+
+	updated, _ := m.Update(key("p"))
+	m = updated.(model)
+	if m.paused || m.textInput.Value() != "p" {
+		t.Errorf("paused %v, input %q: p in the URL box paused the queue", m.paused, m.textInput.Value())
+	}
+
+	m.textInput.SetValue("")
+	m.setFocus(focusQueue)
+	updated, _ = m.Update(key("p"))
+	m = updated.(model)
+	if !m.paused {
+		t.Error("p with the queue focused didn't pause it")
+	}
+}
+
+func TestSubtitleKeyNeedsAListFocused(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+
+	updated, _ := m.Update(key("s"))
+	m = updated.(model)
+	if m.downloadSubs || m.textInput.Value() != "s" {
+		t.Errorf("subs %v, input %q: s in the URL box toggled subtitles", m.downloadSubs, m.textInput.Value())
+	}
+	if _, err := os.Stat(config.DefaultPath()); err == nil {
+		t.Error("typing s saved the config")
+	}
+
+	m.textInput.SetValue("")
+	m.setFocus(focusHistory)
+	updated, _ = m.Update(key("s"))
+	m = updated.(model)
+	if !m.downloadSubs {
+		t.Error("s with the archive focused didn't toggle subtitles")
+	}
+}
@@ -0,0 +1,32 @@
+package tui
+
+import "yeet-tube/downloader"
+
+// checkLive applies a case's live_status before it launches: premieres
+// fail straight away, and live streams are either recorded from the start
+// or rejected, since yt-dlp would otherwise never finish them
+func (m *model) checkLive(vd *VideoDownload, liveStatus string) {
+	switch liveStatus {
+	case downloader.LiveStatusUpcoming:
+		m.rejectCase(vd, "SCHEDULED — NOT YET AVAILABLE")
+		m.status = "⏳ SCHEDULED — NOT YET AVAILABLE • " + vd.Name
+	case downloader.LiveStatusLive:
+		if !m.cfg.LiveFromStart {
+			m.rejectCase(vd, "LIVE BROADCAST • SET live_from_start TO RECORD IT")
+			m.status = "⚠ LIVE BROADCAST REJECTED • " + vd.Name
+			return
+		}
+		vd.Options.LiveFromStart = true
+		m.status = "◉ LIVE BROADCAST • RECORDING FROM THE START • " + vd.Name
+	}
+}
+
+// rejectCase fails a case that never reached yt-dlp
+func (m *model) rejectCase(vd *VideoDownload, reason string) {
+	vd.Queued = false
+	vd.Pending = false
+	vd.Done = true
+	vd.Failed = true
+	vd.Err = reason
+	m.saveQueue(queuePath)
+}
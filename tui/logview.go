@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// openLog shows the full yt-dlp output of vd in a scrollable viewport
+func (m *model) openLog(vd *VideoDownload) {
+	m.logCase = vd
+	m.logView = viewport.New(max(m.windowWidth-4, 1), max(m.windowHeight-6, 1))
+	m.logView.SetContent(strings.Join(vd.FullLog, "\n"))
+	m.logView.GotoBottom()
+}
+
+// refreshLog picks up lines captured since the viewer was opened,
+// following the tail if the user hasn't scrolled up
+func (m *model) refreshLog() {
+	if m.logCase == nil {
+		return
+	}
+	follow := m.logView.AtBottom()
+	m.logView.SetContent(strings.Join(m.logCase.FullLog, "\n"))
+	if follow {
+		m.logView.GotoBottom()
+	}
+}
+
+// updateLog handles key presses while the log viewer is open
+func (m model) updateLog(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.requestQuit()
+	case "esc", "q":
+		m.logCase = nil
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.logView, cmd = m.logView.Update(msg)
+	return m, cmd
+}
+
+// logViewerView renders the full-screen log viewer
+func (m model) logViewerView() string {
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Background(color(m.theme.Accent)).
+		Foreground(color(m.theme.OnAccent)).
+		Padding(0, 1).
+		Width(m.windowWidth).
+		Render("CASE LOG • " + m.logCase.Name)
+
+	body := lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder()).
+		BorderForeground(color(m.theme.Border)).
+		Render(m.logView.View())
+
+	footer := lipgloss.NewStyle().
+		Foreground(color(m.theme.Muted)).
+		Render("↑/↓ SCROLL • PGUP/PGDN PAGE • ESC TO RETURN")
+
+	return title + "\n" + body + "\n" + footer
+}
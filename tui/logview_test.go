@@ -0,0 +1,30 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEnterOpensLogOnlyWhenThereIsOne(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+	quiet := &VideoDownload{URL: "https://example.com/a", Name: "A", Queued: true}
+	m.videoQueue = []*VideoDownload{quiet}
+
+	// no log yet: enter keeps submitting the empty input
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.logCase != nil {
+		t.Fatal("enter opened an empty log")
+	}
+	if m.status != "⚠ INPUT REJECTED • INVALID VARIANT SEQUENCE" {
+		t.Errorf("status = %q, want the empty input rejected", m.status)
+	}
+
+	quiet.FullLog = []string{"[download]  10.0%"}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.logCase != quiet {
+		t.Error("enter didn't open the selected case's log")
+	}
+}
@@ -0,0 +1,44 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// notifyCmd shows a desktop notification; missing notification tools are ignored
+func notifyCmd(title, body string) tea.Cmd {
+	return func() tea.Msg {
+		var cmd *exec.Cmd
+		switch runtime.GOOS {
+		case "darwin":
+			script := fmt.Sprintf("display notification %q with title %q", body, title)
+			cmd = exec.Command("osascript", "-e", script)
+		case "windows":
+			script := fmt.Sprintf(
+				"[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null; "+
+					"$t = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02); "+
+					"$x = $t.GetElementsByTagName('text'); $x.Item(0).InnerText = '%s'; $x.Item(1).InnerText = '%s'; "+
+					"[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('Yeet-Tube').Show([Windows.UI.Notifications.ToastNotification]::new($t))",
+				psQuote(title), psQuote(body),
+			)
+			cmd = exec.Command("powershell", "-NoProfile", "-Command", script)
+		default:
+			cmd = exec.Command("notify-send", title, body)
+		}
+
+		if _, err := exec.LookPath(cmd.Path); err != nil {
+			return nil
+		}
+		cmd.Run()
+		return nil
+	}
+}
+
+// psQuote escapes s for a single-quoted PowerShell string
+func psQuote(s string) string {
+	return strings.ReplaceAll(s, "'", "''")
+}
@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxURLLength caps the URL input. Long enough for URLs dragging a lot of
+// tracking parameters, which NormalizeURL strips later anyway.
+const maxURLLength = 4096
+
+// pasteURL inserts pasted text into the URL input in one go, at the cursor.
+// Whitespace is dropped since terminals often append a newline, and a paste
+// that wouldn't fit is rejected whole rather than silently truncated.
+func (m *model) pasteURL(text string) {
+	text = strings.Join(strings.Fields(text), "")
+	if text == "" {
+		return
+	}
+
+	value := []rune(m.textInput.Value())
+	pasted := []rune(text)
+	if len(value)+len(pasted) > maxURLLength {
+		m.status = fmt.Sprintf("⚠ PASTE REJECTED • URLS ARE LIMITED TO %d CHARACTERS", maxURLLength)
+		return
+	}
+
+	pos := min(m.textInput.Position(), len(value))
+	joined := append(append(value[:pos:pos], pasted...), value[pos:]...)
+	m.textInput.SetValue(string(joined))
+	m.textInput.SetCursor(pos + len(pasted))
+}
+
+// inputWidth is how many characters of the URL input fit in its box; longer
+// values scroll horizontally
+func inputWidth(windowWidth int) int {
+	// box padding on both sides, then the "> " prompt and the cursor
+	return max(int(float64(windowWidth)*0.85)-5, 10)
+}
@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"yeet-tube/downloader"
+)
+
+// running reports whether vd has a live download behind it
+func running(vd *VideoDownload) bool {
+	return vd.ProgressCh != nil && !vd.Done && !vd.Queued && !vd.Interrupted
+}
+
+// pauseQueue stops new cases from starting and suspends the running ones.
+// Where processes can't be suspended they are cancelled instead and
+// restarted with --continue on resume.
+func (m *model) pauseQueue() {
+	m.paused = true
+	paused := 0
+	for _, vd := range m.videoQueue {
+		if !running(vd) || vd.Paused {
+			continue
+		}
+
+		err := downloader.PauseDownload(vd.Options.Process)
+		if errors.Is(err, downloader.ErrPauseUnsupported) {
+			if vd.Cancel != nil {
+				vd.Cancel()
+			}
+			vd.ProgressCh = nil
+			vd.Interrupted = true
+			vd.Options.Resume = true
+			err = nil
+		}
+		if err == nil {
+			vd.Paused = true
+			paused++
+		}
+	}
+	m.status = fmt.Sprintf("⏸ QUEUE PAUSED • %d CASES SUSPENDED • P TO RESUME", paused)
+}
+
+// resumeQueue undoes pauseQueue
+func (m *model) resumeQueue() []tea.Cmd {
+	m.paused = false
+	var cmds []tea.Cmd
+	for _, vd := range m.videoQueue {
+		if !vd.Paused {
+			continue
+		}
+		vd.Paused = false
+		if vd.Interrupted {
+			// cancelled by the fallback, start it again from its partial file
+			cmds = append(cmds, m.start(vd)...)
+			continue
+		}
+		downloader.ResumeDownload(vd.Options.Process)
+	}
+	m.status = "▶ QUEUE RESUMED"
+	return cmds
+}
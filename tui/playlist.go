@@ -0,0 +1,112 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"yeet-tube/downloader"
+)
+
+// playlistPrompt asks which videos of a pasted playlist to queue
+type playlistPrompt struct {
+	url   string
+	force bool // skip duplicate checks
+	input textinput.Model
+}
+
+// openPlaylistPrompt asks for the items of url to queue
+func (m *model) openPlaylistPrompt(url string, force bool) {
+	in := textinput.New()
+	in.Placeholder = "1-10,15 (EMPTY = ALL)"
+	in.CharLimit = 64
+	in.Width = 24
+	in.Focus()
+	m.playlistPrompt = &playlistPrompt{url: url, force: force, input: in}
+	m.textInput.Blur()
+	m.status = "◉ PLAYLIST DETECTED • SELECT VARIANT BRANCHES"
+}
+
+// closePlaylistPrompt hands the keyboard back to the focused pane
+func (m *model) closePlaylistPrompt() {
+	m.playlistPrompt = nil
+	m.restoreFocus()
+}
+
+// updatePlaylistPrompt handles key presses while the playlist range is being entered
+func (m model) updatePlaylistPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.requestQuit()
+	case "esc":
+		m.closePlaylistPrompt()
+		m.status = readyStatus
+		return m, nil
+	case "enter":
+		items := strings.TrimSpace(m.playlistPrompt.input.Value())
+		if items != "" {
+			if err := downloader.ValidatePlaylistItems(items); err != nil {
+				m.status = "⚠ INVALID PLAYLIST RANGE • " + strings.ToUpper(err.Error())
+				return m, nil
+			}
+		}
+		p := m.playlistPrompt
+		m.closePlaylistPrompt()
+		return m, m.expandPlaylist(p.url, p.force, items)
+	}
+
+	var cmd tea.Cmd
+	m.playlistPrompt.input, cmd = m.playlistPrompt.input.Update(msg)
+	return m, cmd
+}
+
+// expandPlaylist resolves the selected items of a playlist, empty items
+// meaning all of it up to MaxPlaylistEntries
+func (m *model) expandPlaylist(url string, force bool, items string) tea.Cmd {
+	m.status = "◉ PLAYLIST DETECTED • RESOLVING VARIANT BRANCHES..."
+	opts := downloader.Options{CookiesFromBrowser: m.cfg.CookiesFromBrowser, Proxy: m.cfg.Proxy, PlaylistItems: items}
+	return expandPlaylistCmd(url, force, opts)
+}
+
+// playlistExpanded asks for confirmation before queueing the resolved
+// videos, leaving out ones already archived unless forced
+func (m *model) playlistExpanded(msg playlistExpandedMsg) {
+	if msg.err != nil {
+		m.status = "⚠ PLAYLIST RESOLUTION FAILED • " + strings.ToUpper(msg.err.Error())
+		return
+	}
+
+	var urls []string
+	for _, url := range msg.urls {
+		if msg.force || m.duplicateReason(url) == "" {
+			urls = append(urls, url)
+		}
+	}
+	skipped := ""
+	if n := len(msg.urls) - len(urls); n > 0 {
+		skipped = fmt.Sprintf(" • %d ALREADY ARCHIVED", n)
+	}
+	if len(msg.urls) >= downloader.MaxPlaylistEntries {
+		skipped += fmt.Sprintf(" (CAPPED AT %d)", downloader.MaxPlaylistEntries)
+	}
+	if len(urls) == 0 {
+		m.status = "⚠ NOTHING TO QUEUE" + skipped
+		return
+	}
+
+	m.prompt = &prompt{
+		question: fmt.Sprintf("QUEUE %d VARIANTS FROM THIS PLAYLIST%s? Y = QUEUE • ANY OTHER KEY CANCELS", len(urls), skipped),
+		actions: map[string]func(m *model) tea.Cmd{
+			"y": func(m *model) tea.Cmd {
+				var cmds []tea.Cmd
+				for _, url := range urls {
+					cmds = append(cmds, m.enqueue(url, nil)...)
+				}
+				m.saveQueue(queuePath)
+				m.status = fmt.Sprintf("✔ PLAYLIST ACCEPTED • %d VARIANTS QUEUED", len(urls)) + skipped
+				return tea.Batch(cmds...)
+			},
+		},
+	}
+}
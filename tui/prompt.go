@@ -0,0 +1,25 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// prompt is a yes/no style question that captures the next key press
+type prompt struct {
+	question string
+	actions  map[string]func(m *model) tea.Cmd // key -> action, any other key cancels
+}
+
+// updatePrompt resolves the pending prompt with the pressed key
+func (m model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.prompt
+	m.prompt = nil
+
+	if action, ok := p.actions[msg.String()]; ok {
+		cmd := action(&m)
+		return m, cmd
+	}
+
+	m.status = "✔ ACTION CANCELLED"
+	return m, nil
+}
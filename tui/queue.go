@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"yeet-tube/downloader"
+)
+
+// queuePath is where unfinished cases are kept between sessions
+const queuePath = "queue.json"
+
+// queuedCase is the on-disk form of a VideoDownload
+type queuedCase struct {
+	URL     string             `json:"url"`
+	Name    string             `json:"name"`
+	Percent float64            `json:"percent"`
+	Done    bool               `json:"done"`
+	Options downloader.Options `json:"options"`
+}
+
+// saveQueue writes unfinished cases to path, pruning completed ones
+func (m model) saveQueue(path string) error {
+	cases := []queuedCase{}
+	for _, vd := range m.videoQueue {
+		if vd.Done || vd.Pending {
+			continue // dry-run entries only live for the session
+		}
+		cases = append(cases, queuedCase{
+			URL:     vd.URL,
+			Name:    vd.Name,
+			Percent: vd.Percent,
+			Done:    vd.Done,
+			Options: vd.Options,
+		})
+	}
+
+	data, err := json.MarshalIndent(cases, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// loadQueue restores unfinished cases from path, marked as interrupted
+func loadQueue(path string) []*VideoDownload {
+	queue := []*VideoDownload{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return queue
+	}
+	var cases []queuedCase
+	if err := json.Unmarshal(data, &cases); err != nil {
+		return queue
+	}
+
+	for _, c := range cases {
+		if c.Done {
+			continue
+		}
+		// the previous session may have left partial files behind
+		c.Options.Resume = true
+		queue = append(queue, &VideoDownload{
+			URL:          c.URL,
+			Name:         c.Name,
+			Percent:      c.Percent,
+			Options:      c.Options,
+			Log:          []string{},
+			TitleFetched: true,
+			Interrupted:  true,
+		})
+	}
+	return queue
+}
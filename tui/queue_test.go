@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"yeet-tube/downloader"
+)
+
+func TestRecoveredCasesContinuePartialFiles(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+
+	running := &VideoDownload{URL: "https://example.com/v", Name: "V", Percent: 0.4, TitleFetched: true, Options: downloader.Options{Format: "mp4"}}
+	m.videoQueue = []*VideoDownload{running}
+	m.saveQueue(queuePath)
+
+	// the next session finds it interrupted
+	m.videoQueue = loadQueue(queuePath)
+	if len(m.videoQueue) != 1 || !m.videoQueue[0].Interrupted {
+		t.Fatalf("queue = %+v, want the case back as interrupted", m.videoQueue)
+	}
+	r := record(t)
+	m.setFocus(focusQueue)
+	updated, _ := m.Update(key("R"))
+	m = updated.(model)
+	launch(t, &m)
+
+	calls := r.downloads()
+	if len(calls) != 1 || !slices.Contains(calls[0], "--continue") {
+		t.Errorf("downloads = %q, want one that continues the partial file", calls)
+	}
+}
+
+func TestRetriedStallsContinuePartialFiles(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+
+	stalled := &VideoDownload{
+		URL:          "https://example.com/v",
+		Name:         "V",
+		TitleFetched: true,
+		Stalled:      true,
+		ProgressCh:   make(chan downloader.ProgressFractionMsg, 1),
+		Options:      downloader.Options{Format: "mp4"},
+	}
+	m.videoQueue = []*VideoDownload{stalled}
+
+	r := record(t)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = updated.(model)
+	launch(t, &m)
+
+	calls := r.downloads()
+	if len(calls) != 1 || !slices.Contains(calls[0], "--continue") {
+		t.Errorf("downloads = %q, want one that continues the partial file", calls)
+	}
+}
+
+func TestNewCasesStartFresh(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+	m.videoQueue = []*VideoDownload{{URL: "https://example.com/v", Name: "V", TitleFetched: true, Queued: true, Options: downloader.Options{Format: "mp4"}}}
+
+	r := record(t)
+	launch(t, &m)
+
+	calls := r.downloads()
+	if len(calls) != 1 || slices.Contains(calls[0], "--continue") {
+		t.Errorf("downloads = %q, want a fresh start", calls)
+	}
+}
@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"yeet-tube/downloader"
+)
+
+// shutdownGrace is how long Shutdown waits for killed downloads to exit
+const shutdownGrace = 3 * time.Second
+
+// requestQuit exits straight away when nothing is downloading, and asks
+// for confirmation first when cases would be interrupted
+func (m model) requestQuit() (tea.Model, tea.Cmd) {
+	active := 0
+	for _, vd := range m.videoQueue {
+		if running(vd) {
+			active++
+		}
+	}
+	if active == 0 {
+		m.saveQueue(queuePath)
+		return m, tea.Quit
+	}
+
+	// the prompt lives in the main view's status line
+	m.picker, m.logCase, m.showHelp = nil, nil, false
+	if m.clipInput != nil {
+		m.closeClipPrompt()
+	}
+	if m.playlistPrompt != nil {
+		m.closePlaylistPrompt()
+	}
+	if m.tagInput != nil {
+		m.closeTagPrompt()
+	}
+
+	quit := func(m *model) tea.Cmd {
+		m.saveQueue(queuePath)
+		return tea.Quit
+	}
+	m.prompt = &prompt{
+		question: fmt.Sprintf("%d DOWNLOADS ACTIVE — QUIT ANYWAY? Y = QUIT (CASES RESUME NEXT TIME) • ANY OTHER KEY CANCELS", active),
+		actions: map[string]func(m *model) tea.Cmd{
+			"y":      quit,
+			"ctrl+c": quit,
+		},
+	}
+	return m, nil
+}
+
+// Shutdown stops every download still running when the program exits, so
+// no yt-dlp process (paused or not) outlives the console. It waits up to
+// shutdownGrace for them to exit; the saved queue brings them back as
+// interrupted cases next time.
+func Shutdown(final tea.Model) {
+	m, ok := final.(model)
+	if !ok {
+		return
+	}
+
+	var pending []chan downloader.ProgressFractionMsg
+	for _, vd := range m.videoQueue {
+		if !running(vd) {
+			continue
+		}
+		if vd.Cancel != nil {
+			vd.Cancel()
+		}
+		downloader.KillDownload(vd.Options.Process)
+		pending = append(pending, vd.ProgressCh)
+	}
+
+	// the downloader closes each channel once its yt-dlp process has exited
+	deadline := time.After(shutdownGrace)
+	for _, ch := range pending {
+		for open := true; open; {
+			select {
+			case _, open = <-ch:
+			case <-deadline:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"yeet-tube/downloader"
+)
+
+// hangingRunner starts commands that run until they are cancelled
+type hangingRunner struct{}
+
+func (hangingRunner) Run(ctx context.Context, name string, args ...string) (io.Reader, io.Reader, func() error, error) {
+	stdout, w := io.Pipe()
+	go func() {
+		<-ctx.Done()
+		w.Close()
+	}()
+	wait := func() error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return stdout, strings.NewReader(""), wait, nil
+}
+
+func isQuit(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	_, ok := cmd().(tea.QuitMsg)
+	return ok
+}
+
+func TestQuitWithoutActiveDownloads(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+	m.videoQueue = []*VideoDownload{{URL: "https://example.com/v", Name: "V", Done: true}}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if !isQuit(cmd) || updated.(model).prompt != nil {
+		t.Error("quitting an idle console asked for confirmation")
+	}
+}
+
+func TestQuitConfirmsActiveDownloads(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+	m.videoQueue = []*VideoDownload{{URL: "https://example.com/v", Name: "V", ProgressCh: make(chan downloader.ProgressFractionMsg, 1)}}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	m = updated.(model)
+	if isQuit(cmd) || m.prompt == nil {
+		t.Fatal("quit with an active download didn't ask first")
+	}
+
+	updated, cmd = m.Update(key("n"))
+	m = updated.(model)
+	if isQuit(cmd) || m.prompt != nil {
+		t.Fatal("declining the prompt didn't cancel the quit")
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	_, cmd = updated.(model).Update(key("y"))
+	if !isQuit(cmd) {
+		t.Error("confirming the prompt didn't quit")
+	}
+}
+
+func TestShutdownWaitsForDownloads(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+	old := downloader.Runner
+	downloader.Runner = hangingRunner{}
+	t.Cleanup(func() { downloader.Runner = old })
+
+	vd := &VideoDownload{URL: "https://example.com/v", Name: "V", TitleFetched: true, Queued: true, Options: downloader.Options{Format: "mp4"}}
+	m.videoQueue = []*VideoDownload{vd}
+	for _, cmd := range m.launchQueued() {
+		cmd()
+	}
+	ch := vd.ProgressCh
+	if !running(vd) {
+		t.Fatal("download didn't start")
+	}
+
+	start := time.Now()
+	Shutdown(m)
+	if time.Since(start) >= shutdownGrace {
+		t.Fatal("Shutdown gave up instead of seeing the download exit")
+	}
+	select {
+	case _, open := <-ch:
+		if open {
+			t.Error("Shutdown returned before the download closed its channel")
+		}
+	default:
+		t.Error("Shutdown returned while the download was still running")
+	}
+}
@@ -0,0 +1,39 @@
+package tui
+
+import "fmt"
+
+// waiting reports whether vd is queued and hasn't been handed to yt-dlp yet
+func waiting(vd *VideoDownload) bool {
+	return vd.Queued && !vd.Done
+}
+
+// moveQueued swaps the selected waiting case with the next waiting case in
+// direction delta, changing which one launches first. Running and finished
+// cases stay where they are.
+func (m *model) moveQueued(delta int) {
+	if m.queueIndex >= len(m.videoQueue) || !waiting(m.videoQueue[m.queueIndex]) {
+		m.status = "⚠ ONLY QUEUED CASES CAN BE REORDERED"
+		return
+	}
+	for j := m.queueIndex + delta; j >= 0 && j < len(m.videoQueue); j += delta {
+		if !waiting(m.videoQueue[j]) {
+			continue
+		}
+		m.videoQueue[m.queueIndex], m.videoQueue[j] = m.videoQueue[j], m.videoQueue[m.queueIndex]
+		m.queueIndex = j
+		m.status = fmt.Sprintf("✔ CASE MOVED TO QUEUE POSITION %d", queuePosition(m.videoQueue, j))
+		m.saveQueue(queuePath)
+		return
+	}
+}
+
+// queuePosition is the 1-based launch order of the waiting case at index i
+func queuePosition(queue []*VideoDownload, i int) int {
+	pos := 1
+	for _, vd := range queue[:i] {
+		if waiting(vd) {
+			pos++
+		}
+	}
+	return pos
+}
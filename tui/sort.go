@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"yeet-tube/downloader"
+)
+
+// sortMode selects how the archive history is ordered
+type sortMode int
+
+const (
+	sortAppended sortMode = iota // order cases were archived in
+	sortDate                     // newest first
+	sortTitle                    // A-Z
+	sortDuration                 // longest first
+	sortSize                     // largest first
+	sortModeCount
+)
+
+// String returns the label shown in the history title
+func (s sortMode) String() string {
+	switch s {
+	case sortDate:
+		return "DATE"
+	case sortTitle:
+		return "TITLE"
+	case sortDuration:
+		return "DURATION"
+	case sortSize:
+		return "SIZE"
+	default:
+		return "APPENDED"
+	}
+}
+
+// next cycles to the following sort mode
+func (s sortMode) next() sortMode {
+	return (s + 1) % sortModeCount
+}
+
+// sortHistory returns a sorted copy of infos, leaving the input untouched
+func sortHistory(infos []downloader.VideoInfo, mode sortMode) []downloader.VideoInfo {
+	sorted := make([]downloader.VideoInfo, len(infos))
+	copy(sorted, infos)
+
+	var less func(a, b downloader.VideoInfo) bool
+	switch mode {
+	case sortDate:
+		less = func(a, b downloader.VideoInfo) bool { return a.DownloadedAt.After(b.DownloadedAt) }
+	case sortTitle:
+		less = func(a, b downloader.VideoInfo) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) }
+	case sortDuration:
+		less = func(a, b downloader.VideoInfo) bool { return a.Duration > b.Duration }
+	case sortSize:
+		less = func(a, b downloader.VideoInfo) bool { return a.Filesize > b.Filesize }
+	default:
+		return sorted
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	return sorted
+}
+
+// etaSeconds converts a yt-dlp ETA such as "05:13" or "1:02:03" to seconds,
+// returning -1 when it can't be parsed
+func etaSeconds(eta string) int {
+	total := 0
+	for _, part := range strings.Split(eta, ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return -1
+		}
+		total = total*60 + n
+	}
+	return total
+}
+
+// hasETA reports whether vd is still running with a usable ETA
+func hasETA(vd *VideoDownload) bool {
+	return !vd.Done && vd.ETA != "" && vd.ETASeconds >= 0
+}
+
+// queueOrder returns the indices of queue in display order. With byETA the
+// soonest-finishing cases come first and those without an ETA keep their
+// relative order at the end; the queue itself is never reordered.
+func queueOrder(queue []*VideoDownload, byETA bool) []int {
+	order := make([]int, len(queue))
+	for i := range order {
+		order[i] = i
+	}
+	if !byETA {
+		return order
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		va, vb := queue[order[a]], queue[order[b]]
+		if hasETA(va) != hasETA(vb) {
+			return hasETA(va)
+		}
+		return hasETA(va) && va.ETASeconds < vb.ETASeconds
+	})
+	return order
+}
+
+// queueOrderLabel is appended to the queue title while ETA ordering is on
+func queueOrderLabel(byETA bool) string {
+	if byETA {
+		return " • QUEUE: ETA"
+	}
+	return ""
+}
+
+// moveQueueSelection moves the queue cursor by delta rows in display order
+func (m *model) moveQueueSelection(delta int) {
+	order := queueOrder(m.videoQueue, m.sortQueueByETA)
+	for pos, i := range order {
+		if i == m.queueIndex {
+			if next := pos + delta; next >= 0 && next < len(order) {
+				m.queueIndex = order[next]
+			}
+			return
+		}
+	}
+}
+
+// pinFavorites moves favorites to the front, keeping the sort order within
+// favorites and within the rest
+func pinFavorites(infos []downloader.VideoInfo) []downloader.VideoInfo {
+	sort.SliceStable(infos, func(i, j int) bool { return infos[i].IsFavorite && !infos[j].IsFavorite })
+	return infos
+}
@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"yeet-tube/downloader"
+)
+
+// stallExemptFraction is where post-processing starts on the bar. Merges and
+// conversions can sit still for minutes, so they are never flagged.
+const stallExemptFraction = 0.9
+
+// checkStall flags vd once its progress has been flat for cfg.StallTimeout
+func (m *model) checkStall(vd *VideoDownload, now time.Time) {
+	if !running(vd) {
+		return
+	}
+	if vd.Percent != vd.LastPercent || vd.ProgressAt.IsZero() || vd.Paused || !vd.TitleFetched {
+		vd.LastPercent, vd.ProgressAt = vd.Percent, now
+		vd.Stalled = false
+		return
+	}
+
+	limit := m.cfg.StallTimeout()
+	if vd.Stalled || limit <= 0 || vd.Percent >= stallExemptFraction {
+		return
+	}
+	if now.Sub(vd.ProgressAt) >= limit {
+		vd.Stalled = true
+		m.status = "⚠ STALLED • " + vd.Name + " • SELECT IT AND PRESS CTRL+R TO RETRY OR X TO ABORT"
+	}
+}
+
+// retryStalled kills a stalled case and starts it again from its partial file
+func (m *model) retryStalled(vd *VideoDownload) []tea.Cmd {
+	if !vd.Stalled || !running(vd) {
+		m.status = "⚠ SELECTED CASE IS NOT STALLED"
+		return nil
+	}
+	if vd.Cancel != nil {
+		vd.Cancel()
+	}
+	downloader.KillDownload(vd.Options.Process)
+
+	// the old attempt still reports on its own channel; nobody listens anymore
+	vd.ProgressCh = nil
+	vd.Stalled = false
+	vd.ProgressAt = time.Time{}
+	vd.Options.Resume = true
+	m.status = "↻ RETRYING STALLED CASE • " + vd.Name
+	return m.start(vd)
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"yeet-tube/downloader"
+)
+
+// statsView renders the archive totals shown under the preview
+func statsView(stats downloader.Stats, theme Theme) string {
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(color(theme.Accent)).
+		Render("ARCHIVE STATISTICS")
+
+	if stats.Count == 0 {
+		return title + "\n\nNO ARCHIVED CASES"
+	}
+
+	return title + "\n\n" + fmt.Sprintf(
+		"CASES ARCHIVED: %d\nTOTAL SIZE: %s\nTOTAL DURATION: %s\nFIRST CASE: %s\nLATEST CASE: %s",
+		stats.Count,
+		humanSize(stats.TotalSize),
+		formatDuration(stats.TotalDuration),
+		stats.Oldest.Format("2006-01-02"),
+		stats.Newest.Format("2006-01-02"),
+	)
+}
+
+// sourcesView renders the busiest source sites, at most rows of them
+func sourcesView(breakdown map[string]downloader.Stats, rows int, theme Theme) string {
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(color(theme.Accent)).
+		Render("TOP SOURCES")
+
+	if len(breakdown) == 0 {
+		return title + "\n\nNO ARCHIVED CASES"
+	}
+
+	sources := slices.Collect(maps.Keys(breakdown))
+	slices.SortFunc(sources, func(a, b string) int {
+		if c := cmp.Compare(breakdown[b].Count, breakdown[a].Count); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+
+	lines := []string{}
+	for _, source := range sources[:min(rows, len(sources))] {
+		s := breakdown[source]
+		lines = append(lines, fmt.Sprintf("%-10s %3d • %s",
+			truncateString(strings.ToUpper(source), 10), s.Count, humanSize(s.TotalSize)))
+	}
+	return title + "\n\n" + strings.Join(lines, "\n")
+}
+
+// formatDuration renders seconds as HH:MM:SS
+func formatDuration(seconds float64) string {
+	total := int(seconds)
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total/60%60, total%60)
+}
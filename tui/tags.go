@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"yeet-tube/downloader"
+)
+
+// openTagPrompt asks for a tag to toggle on the selected archived case
+func (m *model) openTagPrompt() {
+	if len(m.visibleHistory()) == 0 {
+		m.status = "⚠ NO ARCHIVED CASE SELECTED"
+		return
+	}
+
+	ti := textinput.New()
+	ti.Placeholder = "music"
+	ti.CharLimit = 32
+	ti.Width = 20
+	ti.Focus()
+	m.tagInput = &ti
+	m.textInput.Blur()
+}
+
+// closeTagPrompt hands the keyboard back to the focused pane
+func (m *model) closeTagPrompt() {
+	m.tagInput = nil
+	m.restoreFocus()
+}
+
+// updateTag handles key presses while a tag is being entered
+func (m model) updateTag(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.requestQuit()
+	case "esc":
+		m.closeTagPrompt()
+		m.status = readyStatus
+		return m, nil
+	case "enter":
+		tag := downloader.NormalizeTag(m.tagInput.Value())
+		m.closeTagPrompt()
+		if tag == "" {
+			m.status = readyStatus
+			return m, nil
+		}
+		m.toggleTag(tag)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	*m.tagInput, cmd = m.tagInput.Update(msg)
+	return m, cmd
+}
+
+// toggleTag adds or removes tag on the selected case and reloads history
+func (m *model) toggleTag(tag string) {
+	visible := m.visibleHistory()
+	if len(visible) == 0 {
+		return
+	}
+	info := visible[m.selectedIndex]
+
+	added, err := downloader.ToggleTag(m.cfg.HistoryPath, info.URL, tag)
+	if err != nil {
+		m.status = "⚠ TAG NOT SAVED • " + strings.ToUpper(err.Error())
+		return
+	}
+
+	m.status = "✔ TAG #" + strings.ToUpper(tag) + " REMOVED"
+	if added {
+		m.status = "✔ TAG #" + strings.ToUpper(tag) + " ADDED"
+	}
+	m.reloadHistory()
+	m.clampSelection()
+}
@@ -0,0 +1,100 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// gradient is the start and end color of a progress bar fill
+type gradient struct {
+	Start, End string
+}
+
+// Theme holds every color the console draws with
+type Theme struct {
+	Name string
+
+	Border   string // box borders
+	Accent   string // headings, title bars and key names
+	OnAccent string // text drawn on an Accent background
+	Text     string // secondary text such as the hex dump
+	Muted    string // hints and footers
+	Error    string
+	Info     string // format badges
+
+	GradientStart, GradientEnd string // running progress bars
+
+	Done   gradient // finished progress bars
+	Failed gradient // failed progress bars
+}
+
+// themes are the built-in color schemes, cycled with T. The first one is
+// the default.
+var themes = []Theme{
+	{
+		Name:          "tva",
+		Border:        "#F9BE5E",
+		Accent:        "#F9BE5E",
+		OnAccent:      "#1A1A1A",
+		Text:          "#FFcc99",
+		Muted:         "#888888",
+		Error:         "#E06C75",
+		Info:          "#56B6C2",
+		GradientStart: "#F9BE5E",
+		GradientEnd:   "#d98057",
+		Done:          gradient{"#98C379", "#56B6C2"},
+		Failed:        gradient{"#E06C75", "#BE5046"},
+	},
+	{
+		// mid greys that stay readable on both dark and light terminals
+		Name:          "mono",
+		Border:        "#808080",
+		Accent:        "#6C6C6C",
+		OnAccent:      "#FFFFFF",
+		Text:          "#8A8A8A",
+		Muted:         "#9E9E9E",
+		Error:         "#B05050",
+		Info:          "#6C6C6C",
+		GradientStart: "#A8A8A8",
+		GradientEnd:   "#585858",
+		Done:          gradient{"#7A9A7A", "#5A7A5A"},
+		Failed:        gradient{"#B05050", "#803030"},
+	},
+}
+
+// themeByName returns the built-in theme called name, or the default
+func themeByName(name string) Theme {
+	for _, t := range themes {
+		if t.Name == name {
+			return t
+		}
+	}
+	return themes[0]
+}
+
+// nextTheme returns the built-in theme after t
+func nextTheme(t Theme) Theme {
+	for i := range themes {
+		if themes[i].Name == t.Name {
+			return themes[(i+1)%len(themes)]
+		}
+	}
+	return themes[0]
+}
+
+// color wraps one of the theme's hex strings for lipgloss
+func color(c string) lipgloss.Color {
+	return lipgloss.Color(c)
+}
+
+// newBar returns a progress bar colored for vd's state
+func (t Theme) newBar(vd *VideoDownload) progress.Model {
+	g := gradient{t.GradientStart, t.GradientEnd}
+	switch {
+	case vd.Failed:
+		g = t.Failed
+	case vd.Done:
+		g = t.Done
+	}
+	return progress.New(progress.WithScaledGradient(g.Start, g.End))
+}
@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"strings"
+)
+
+// asciiRamp maps brightness (dark to light) to characters
+const asciiRamp = " .:-=+*#%@"
+
+// thumbnailsSupported reports whether the terminal can show the ASCII preview
+func thumbnailsSupported() bool {
+	term := os.Getenv("TERM")
+	return term != "" && term != "dumb"
+}
+
+// asciiThumbnail renders the image at path as width x height characters
+func asciiThumbnail(path string, width, height int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return "", err
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 || width <= 0 || height <= 0 {
+		return "", fmt.Errorf("empty thumbnail")
+	}
+
+	var rows []string
+	for y := 0; y < height; y++ {
+		var row strings.Builder
+		for x := 0; x < width; x++ {
+			px := bounds.Min.X + x*bounds.Dx()/width
+			py := bounds.Min.Y + y*bounds.Dy()/height
+			r, g, b, _ := img.At(px, py).RGBA()
+
+			// perceived luminance, 0..65535
+			lum := (299*r + 587*g + 114*b) / 1000
+			row.WriteByte(asciiRamp[int(lum)*(len(asciiRamp)-1)/65535])
+		}
+		rows = append(rows, row.String())
+	}
+	return strings.Join(rows, "\n"), nil
+}
+
+// thumbnailView returns the cached ASCII preview for path, or "" if it can't be shown
+func (m model) thumbnailView(path string, width, height int) string {
+	if path == "" || !thumbnailsSupported() {
+		return ""
+	}
+
+	key := fmt.Sprintf("%s@%dx%d", path, width, height)
+	if art, ok := m.thumbCache[key]; ok {
+		return art
+	}
+
+	art, err := asciiThumbnail(path, width, height)
+	if err != nil {
+		art = ""
+	}
+	m.thumbCache[key] = art
+	return art
+}
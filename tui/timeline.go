@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"yeet-tube/downloader"
+)
+
+// sparkBlocks are the bar heights used by sparkline, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// maxTimelineDays bounds how far back the activity timeline reaches
+const maxTimelineDays = 90
+
+// The sources panel takes sourcesWidth columns (gap included) from the
+// timeline, and is only shown while minTimelineWidth days still fit
+const (
+	sourcesWidth     = 30
+	minTimelineWidth = 30
+)
+
+// dailyCounts buckets infos by local calendar day over the days ending
+// today, oldest first
+func dailyCounts(infos []downloader.VideoInfo, days int, now time.Time) []int {
+	counts := make([]int, days)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, info := range infos {
+		t := info.DownloadedAt.In(now.Location())
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location())
+		ago := int(today.Sub(day).Hours() / 24)
+		if ago >= 0 && ago < days {
+			counts[days-1-ago]++
+		}
+	}
+	return counts
+}
+
+// sparkline draws one block per count, scaled to the largest; empty days are blank
+func sparkline(counts []int) string {
+	peak := slices.Max(counts)
+	var b strings.Builder
+	for _, c := range counts {
+		if c == 0 || peak == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		b.WriteRune(sparkBlocks[(c*len(sparkBlocks)-1)/peak])
+	}
+	return b.String()
+}
+
+// timelineView renders archive activity per day in width columns
+func timelineView(infos []downloader.VideoInfo, width int, now time.Time, theme Theme) string {
+	days := min(max(width, 1), maxTimelineDays)
+
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(color(theme.Accent)).
+		Render(fmt.Sprintf("ACTIVITY • LAST %d DAYS", days))
+
+	counts := dailyCounts(infos, days, now)
+	spark := lipgloss.NewStyle().
+		Foreground(color(theme.GradientEnd)).
+		Render(sparkline(counts))
+
+	start := now.AddDate(0, 0, -(days - 1)).Format("01-02")
+	end := now.Format("01-02")
+	axis := start + strings.Repeat(" ", max(days-len(start)-len(end), 1)) + end
+
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	summary := fmt.Sprintf("PEAK: %d/DAY • %d IN WINDOW", slices.Max(counts), total)
+
+	return title + "\n\n" + spark + "\n" + axis + "\n" + summary
+}
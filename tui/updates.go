@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"yeet-tube/downloader"
+)
+
+type updatesCheckedMsg struct {
+	better  map[string]bool // result for every checked record, by recordKey
+	failed  int
+	checked int
+	manual  bool // asked for with "W", so report even when nothing changed
+}
+
+// checkUpdatesCmd runs CheckForUpdate on each record in turn, keeping the
+// load to one yt-dlp process
+func checkUpdatesCmd(infos []downloader.VideoInfo, opts downloader.Options, manual bool) tea.Cmd {
+	return func() tea.Msg {
+		msg := updatesCheckedMsg{better: map[string]bool{}, checked: len(infos), manual: manual}
+		for _, info := range infos {
+			better, err := downloader.CheckForUpdate(info, opts)
+			if err != nil {
+				msg.failed++
+				continue
+			}
+			msg.better[recordKey(info)] = better
+		}
+		return msg
+	}
+}
+
+// checkWatched checks every watched record, or returns nil when there are none
+func (m *model) checkWatched(manual bool) tea.Cmd {
+	var watched []downloader.VideoInfo
+	for _, info := range m.history {
+		if info.WatchUpdates {
+			watched = append(watched, info)
+		}
+	}
+	if len(watched) == 0 {
+		if manual {
+			m.status = "⚠ NO WATCHED CASES • W WATCHES THE SELECTED ONE"
+		}
+		return nil
+	}
+	if manual {
+		m.status = fmt.Sprintf("◉ CHECKING %d WATCHED CASES FOR BETTER FORMATS...", len(watched))
+	}
+	return checkUpdatesCmd(watched, m.updateOptions(), manual)
+}
+
+// updateOptions are the limits a re-download would use right now
+func (m model) updateOptions() downloader.Options {
+	return downloader.Options{
+		MaxHeight:          m.maxHeight,
+		NoFFmpeg:           m.ffmpegVersion == "",
+		CookiesFromBrowser: m.cfg.CookiesFromBrowser,
+		Proxy:              m.cfg.Proxy,
+	}
+}
+
+// updatesChecked badges the records that have a better version now
+func (m *model) updatesChecked(msg updatesCheckedMsg) {
+	found := 0
+	for key, better := range msg.better {
+		if better {
+			m.updates[key] = true
+			found++
+		} else {
+			delete(m.updates, key)
+		}
+	}
+
+	switch {
+	case found > 0:
+		m.status = fmt.Sprintf("⬆ %d WATCHED CASES HAVE A BETTER FORMAT • R TO RE-DOWNLOAD", found)
+	case msg.manual:
+		m.status = fmt.Sprintf("✔ %d WATCHED CASES UP TO DATE", msg.checked-msg.failed)
+	default:
+		return
+	}
+	if msg.failed > 0 {
+		m.status += fmt.Sprintf(" • %d COULD NOT BE CHECKED", msg.failed)
+	}
+}
+
+// toggleWatch opts the selected record in or out of update checks,
+// checking it straight away when it is added
+func (m *model) toggleWatch() tea.Cmd {
+	visible := m.visibleHistory()
+	if len(visible) == 0 {
+		return nil
+	}
+	info := visible[m.selectedIndex]
+
+	watching, err := downloader.ToggleWatch(m.cfg.HistoryPath, info.URL)
+	if err != nil {
+		m.status = "⚠ WATCH NOT SAVED • " + strings.ToUpper(err.Error())
+		return nil
+	}
+	m.reloadHistory()
+
+	if !watching {
+		delete(m.updates, recordKey(info))
+		m.status = "✔ STOPPED WATCHING FOR BETTER FORMATS"
+		return nil
+	}
+	m.status = "◉ WATCHING FOR BETTER FORMATS • CHECKING NOW..."
+	return checkUpdatesCmd([]downloader.VideoInfo{info}, m.updateOptions(), true)
+}
@@ -0,0 +1,35 @@
+package tui
+
+import (
+	"os"
+	"testing"
+)
+
+func TestWatchKeysNeedAListFocused(t *testing.T) {
+	m := testModel(t, 120, 40, nil)
+	record := `[{"url":"https://example.com/v","title":"V"}]`
+	if err := os.WriteFile(m.cfg.HistoryPath, []byte(record), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m.reloadHistory()
+
+	for _, k := range []string{"w", "W"} {
+		m.textInput.SetValue("")
+		updated, _ := m.Update(key(k))
+		m = updated.(model)
+		if m.textInput.Value() != k {
+			t.Errorf("%s in the URL box acted as a hotkey", k)
+		}
+	}
+	if data, _ := os.ReadFile(m.cfg.HistoryPath); string(data) != record {
+		t.Errorf("typing w rewrote the history: %s", data)
+	}
+
+	m.textInput.SetValue("")
+	m.setFocus(focusHistory)
+	updated, _ := m.Update(key("w"))
+	m = updated.(model)
+	if !m.history[0].WatchUpdates {
+		t.Error("w with the archive focused didn't watch the selected case")
+	}
+}
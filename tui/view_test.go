@@ -0,0 +1,60 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"yeet-tube/config"
+	"yeet-tube/downloader"
+)
+
+func TestViewFlaggedHistoryAtMinimumSize(t *testing.T) {
+	for _, ratio := range []float64{minLeftPaneRatio, 0.35} {
+		m := testModel(t, minWindowWidth, minWindowHeight, func(cfg *config.Config) { cfg.LeftPaneRatio = ratio })
+		info := downloader.VideoInfo{URL: "https://example.com/v", Title: "A rather long archived case title", IsFavorite: true}
+		m.history = []downloader.VideoInfo{info}
+		m.integrity = map[string]string{recordKey(info): downloader.VerifySizeMismatch.String()}
+		m.updates[recordKey(info)] = true
+
+		view := m.View()
+		if !strings.Contains(view, "A RA") {
+			t.Errorf("ratio %.2f: history title missing from view", ratio)
+		}
+	}
+}
+
+func TestViewTooSmall(t *testing.T) {
+	sizes := [][2]int{{0, 0}, {1, 1}, {20, 5}, {minWindowWidth - 1, minWindowHeight}, {minWindowWidth, minWindowHeight - 1}, {200, 10}}
+	for _, size := range sizes {
+		m := testModel(t, size[0], size[1], nil)
+		m.videoQueue = []*VideoDownload{{URL: "https://example.com/v", Name: "A queued case", TitleFetched: true, Queued: true}}
+		m.history = []downloader.VideoInfo{{URL: "https://example.com/w", Title: "An archived case"}}
+
+		view := m.View()
+		if !strings.Contains(view, "TERMINAL TOO SMALL") {
+			t.Errorf("%dx%d: view doesn't ask for a bigger terminal", size[0], size[1])
+		}
+		if strings.Contains(view, "A QUEUED CASE") {
+			t.Errorf("%dx%d: console rendered below the minimum size", size[0], size[1])
+		}
+	}
+
+	m := testModel(t, minWindowWidth, minWindowHeight, nil)
+	if strings.Contains(m.View(), "TERMINAL TOO SMALL") {
+		t.Errorf("%dx%d is the minimum but still too small", minWindowWidth, minWindowHeight)
+	}
+}
+
+func TestOverlaysRenderInTinyTerminals(t *testing.T) {
+	vd := &VideoDownload{URL: "https://example.com/v", Name: "V", FullLog: []string{"[download]  10.0%"}}
+	for _, size := range [][2]int{{0, 0}, {1, 1}, {10, 3}} {
+		m := testModel(t, size[0], size[1], nil)
+		m.showHelp = true
+		m.View()
+
+		m = testModel(t, size[0], size[1], nil)
+		m.videoQueue = []*VideoDownload{vd}
+		m.openLog(vd)
+		m.View()
+	}
+}